@@ -0,0 +1,97 @@
+// cmd/client.go
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s-reporter/utils"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+)
+
+// manifestsClient is the in-memory clientset of the --from-files, --helm-chart and --kustomize
+// manifests, loaded once for all the commands of run-all.
+var manifestsClient kubernetes.Interface
+
+// fromManifests reports whether the commands read manifests instead of a cluster.
+func fromManifests(cmd *cobra.Command) bool {
+	fromFiles, _ := cmd.Flags().GetStringSlice("from-files")
+	helmCharts, _ := cmd.Flags().GetStringSlice("helm-chart")
+	kustomizations, _ := cmd.Flags().GetStringSlice("kustomize")
+	return len(fromFiles) > 0 || len(helmCharts) > 0 || len(kustomizations) > 0
+}
+
+// validateValues checks that the --values files have a single --helm-chart to apply to: the values
+// of one chart rarely fit another, so charts needing their own values are reported by separate runs.
+func validateValues(helmCharts []string, valuesFiles []string) error {
+	if len(valuesFiles) == 0 {
+		return nil
+	}
+	switch len(helmCharts) {
+	case 0:
+		return errors.New("--values sets the values of a chart, it requires --helm-chart")
+	case 1:
+		return nil
+	default:
+		return fmt.Errorf("--values applies to a single --helm-chart, got %d charts: run k8s-reporter once per chart", len(helmCharts))
+	}
+}
+
+// kubernetesClient returns the clientset the commands read resources from: the manifests of
+// --from-files and those rendered from --helm-chart and --kustomize when one of them is set, or
+// the cluster of --kubeconfig. The LimitRanges of every namespace are listed once upfront, for the
+// defaults of all the workloads of the run.
+func kubernetesClient(cmd *cobra.Command) (kubernetes.Interface, error) {
+	clientset, err := newKubernetesClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+	utils.PrefetchNamespaceDefaults(clientset)
+	return clientset, nil
+}
+
+// newKubernetesClient builds the clientset of kubernetesClient.
+func newKubernetesClient(cmd *cobra.Command) (kubernetes.Interface, error) {
+	if !fromManifests(cmd) {
+		kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+		utils.Info("Building Kubernetes clientset")
+		return utils.GetKubernetesClient(kubeconfig)
+	}
+	if manifestsClient != nil {
+		return manifestsClient, nil
+	}
+
+	fromFiles, _ := cmd.Flags().GetStringSlice("from-files")
+	helmCharts, _ := cmd.Flags().GetStringSlice("helm-chart")
+	valuesFiles, _ := cmd.Flags().GetStringSlice("values")
+	kustomizations, _ := cmd.Flags().GetStringSlice("kustomize")
+	var rendered []utils.Manifest
+	for _, chart := range helmCharts {
+		utils.Info("Rendering Helm chart", zap.String("chart", chart))
+		manifests, err := utils.RenderHelmChart(chart, valuesFiles)
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, manifests...)
+	}
+	for _, kustomization := range kustomizations {
+		utils.Info("Building kustomization", zap.String("path", kustomization))
+		manifests, err := utils.RenderKustomization(kustomization)
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, manifests...)
+	}
+
+	utils.Info("Loading manifests", zap.Strings("paths", fromFiles))
+	clientset, err := utils.LoadManifests(fromFiles, rendered)
+	if err != nil {
+		return nil, err
+	}
+	manifestsClient = clientset
+	return manifestsClient, nil
+}
@@ -0,0 +1,34 @@
+// cmd/client_test.go
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateValues(t *testing.T) {
+	for name, test := range map[string]struct {
+		helmCharts  []string
+		valuesFiles []string
+		want        string
+	}{
+		"no values":            {helmCharts: []string{"./a", "./b"}},
+		"no chart nor values":  {},
+		"values of a chart":    {helmCharts: []string{"./a"}, valuesFiles: []string{"base.yaml", "prod.yaml"}},
+		"values without chart": {valuesFiles: []string{"prod.yaml"}, want: "requires --helm-chart"},
+		"values of two charts": {helmCharts: []string{"./a", "./b"}, valuesFiles: []string{"prod.yaml"}, want: "single --helm-chart, got 2 charts"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := validateValues(test.helmCharts, test.valuesFiles)
+			switch {
+			case test.want == "" && err != nil:
+				t.Errorf("validateValues: %v", err)
+			case test.want != "" && err == nil:
+				t.Errorf("validateValues succeeded, want an error about %q", test.want)
+			case test.want != "" && !strings.Contains(err.Error(), test.want):
+				t.Errorf("error %q does not mention %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// cmd/cronjobs.go
+
+package cmd
+
+import (
+	"k8s-reporter/handlers"
+	"k8s-reporter/utils"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// cronjobsCmd represents the cronjobs command
+var cronjobsCmd = &cobra.Command{
+	Use:   "cronjobs",
+	Short: "Export CronJobs to a report",
+	Long:  `Export CronJobs to a report will fetch all the CronJobs from a Kubernetes cluster and write their details to a report (an Excel file by default, see --output).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clientset, err := kubernetesClient(cmd)
+		if err != nil {
+			utils.Error("Error building Kubernetes clientset", zap.Error(err))
+			return err
+		}
+
+		utils.Info("Fetching CronJobs")
+		cronJobHandler := &handlers.CronJobHandler{}
+		if err := cronJobHandler.FetchResources(clientset); err != nil {
+			utils.Error("Error fetching CronJobs", zap.Error(err))
+			return err
+		}
+
+		writer, err := openReportWriter(cmd)
+		if err != nil {
+			utils.Error("Failed to open report writer", zap.Error(err))
+			return err
+		}
+
+		utils.Info("Writing CronJobs data to report")
+		if err := cronJobHandler.WriteReport(writer, "CronJobs"); err != nil {
+			utils.Error("Error writing report", zap.Error(err))
+			return err
+		}
+
+		utils.Info("CronJobs data written to report successfully.")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cronjobsCmd)
+	cronjobsCmd.Flags().String("kubeconfig", "", "Path to the kubeconfig file (optional if environment variable KUBECONFIG is set)")
+}
@@ -13,46 +13,45 @@ import (
 // daemonsetsCmd represents the daemonsets command
 var daemonsetsCmd = &cobra.Command{
 	Use:   "daemonsets",
-	Short: "Export DaemonSets to an Excel sheet",
-	Long: `Export DaemonSets to an Excel sheet will fetch all the DaemonSets from a Kubernetes cluster
-and write their details to a specified sheet within an Excel file.`,
-	Example: `# Export DaemonSets to an Excel sheet using the default kubeconfig
+	Short: "Export DaemonSets to a report",
+	Long: `Export DaemonSets to a report will fetch all the DaemonSets from a Kubernetes cluster
+and write their details to a report (a sheet of an Excel file by default, see --output).`,
+	Example: `# Export DaemonSets to a report using the default kubeconfig
 k8s-reporter daemonsets
 
-# Export DaemonSets to an Excel sheet using a specific kubeconfig
-k8s-reporter daemonsets --kubeconfig=/path/to/kubeconfig`,
-	Run: func(cmd *cobra.Command, args []string) {
-		kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
-		utils.Info("Building Kubernetes clientset")
-		clientset, err := utils.GetKubernetesClient(kubeconfig)
+# Export DaemonSets to a report using a specific kubeconfig
+k8s-reporter daemonsets --kubeconfig=/path/to/kubeconfig
+
+# Export DaemonSets as JSON
+k8s-reporter daemonsets --output json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clientset, err := kubernetesClient(cmd)
 		if err != nil {
-			utils.Fatal("Error building Kubernetes clientset", zap.Error(err))
+			utils.Error("Error building Kubernetes clientset", zap.Error(err))
+			return err
 		}
 
 		daemonSetHandler := &handlers.DaemonSetHandler{}
 		utils.Info("Fetching DaemonSets")
 		if err := daemonSetHandler.FetchResources(clientset); err != nil {
-			utils.Fatal("Error fetching DaemonSets", zap.Error(err))
-		}
-
-		excelManager := utils.GetExcelFileManager()
-		if err := excelManager.OpenOrCreateExcelFile("k8s_report.xlsx"); err != nil {
-			utils.Fatal("Failed to open or create Excel file", zap.Error(err))
+			utils.Error("Error fetching DaemonSets", zap.Error(err))
+			return err
 		}
 
-		excelFile := excelManager.GetExcelFile()
-
-		utils.Info("Adding DaemonSets sheet to Excel file")
-		if err := utils.AddSheetToExcelFile(excelFile, "DaemonSets", handlers.DaemonSetHeaders); err != nil {
-			utils.Fatal("Failed to add sheet to Excel file", zap.Error(err))
+		writer, err := openReportWriter(cmd)
+		if err != nil {
+			utils.Error("Failed to open report writer", zap.Error(err))
+			return err
 		}
 
-		utils.Info("Writing DaemonSets data to Excel sheet")
-		if err := daemonSetHandler.WriteExcel(clientset, excelFile, "DaemonSets"); err != nil {
-			utils.Fatal("Error writing to Excel", zap.Error(err))
+		utils.Info("Writing DaemonSets data to report")
+		if err := daemonSetHandler.WriteReport(writer, "DaemonSets"); err != nil {
+			utils.Error("Error writing report", zap.Error(err))
+			return err
 		}
 
-		utils.Info("DaemonSets data written to Excel file successfully")
+		utils.Info("DaemonSets data written to report successfully")
+		return nil
 	},
 }
 
@@ -13,47 +13,38 @@ import (
 // deploymentsCmd represents the deployments command
 var deploymentsCmd = &cobra.Command{
 	Use:   "deployments",
-	Short: "Export Deployments to an Excel sheet",
-	Long:  `Export Deployments to an Excel sheet will fetch all the Deployments from a Kubernetes cluster and write their details to an Excel file.`,
+	Short: "Export Deployments to a report",
+	Long:  `Export Deployments to a report will fetch all the Deployments from a Kubernetes cluster and write their details to a report (an Excel file by default, see --output).`,
 	RunE:  deployments,
 }
 
 func deployments(cmd *cobra.Command, args []string) error {
-	kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
-	utils.Info("Building Kubernetes clientset")
-	clientset, err := utils.GetKubernetesClient(kubeconfig)
+	clientset, err := kubernetesClient(cmd)
 	if err != nil {
-		utils.Fatal("Error building Kubernetes clientset", zap.Error(err))
+		utils.Error("Error building Kubernetes clientset", zap.Error(err))
 		return err
 	}
 
 	utils.Info("Fetching Deployments")
 	deploymentHandler := &handlers.DeploymentHandler{}
 	if err := deploymentHandler.FetchResources(clientset); err != nil {
-		utils.Fatal("Error fetching Deployments", zap.Error(err))
+		utils.Error("Error fetching Deployments", zap.Error(err))
 		return err
 	}
 
-	excelManager := utils.GetExcelFileManager()
-	if err := excelManager.OpenOrCreateExcelFile("k8s_report.xlsx"); err != nil {
-		utils.Fatal("Failed to open or create Excel file", zap.Error(err))
-		return err
-	}
-
-	excelFile := excelManager.GetExcelFile()
-	utils.Info("Adding Deployments sheet to Excel file")
-	if err := utils.AddSheetToExcelFile(excelFile, "Deployments", handlers.DeploymentHeaders); err != nil {
-		utils.Fatal("Failed to add sheet to Excel file", zap.Error(err))
+	writer, err := openReportWriter(cmd)
+	if err != nil {
+		utils.Error("Failed to open report writer", zap.Error(err))
 		return err
 	}
 
-	utils.Info("Writing Deployments data to Excel sheet")
-	if err := deploymentHandler.WriteExcel(clientset, excelFile, "Deployments"); err != nil {
-		utils.Fatal("Error writing to Excel", zap.Error(err))
+	utils.Info("Writing Deployments data to report")
+	if err := deploymentHandler.WriteReport(writer, "Deployments"); err != nil {
+		utils.Error("Error writing report", zap.Error(err))
 		return err
 	}
 
-	utils.Info("Deployments data written to Excel file successfully")
+	utils.Info("Deployments data written to report successfully")
 	return nil
 }
 
@@ -0,0 +1,72 @@
+// cmd/gate.go
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"k8s-reporter/handlers"
+	"k8s-reporter/utils"
+
+	"go.uber.org/zap"
+)
+
+// Exit codes of k8s-reporter, so that pipelines can tell failing checks from a failing tool.
+const (
+	// ExitOK is returned when the report was written and no finding reached --fail-on.
+	ExitOK = 0
+	// ExitError is returned when k8s-reporter failed, e.g. the cluster could not be reached.
+	ExitError = 1
+	// ExitFindings is returned when some findings are at least as severe as --fail-on.
+	ExitFindings = 2
+)
+
+// stdoutSummary is the --summary-file value writing the summary to the standard output.
+const stdoutSummary = "-"
+
+// validateFailOn checks the --fail-on flag.
+func validateFailOn(failOn string) error {
+	if failOn != "" && !utils.ValidSeverity(failOn) {
+		return fmt.Errorf("unsupported --fail-on %q, must be one of: %s, %s, %s", failOn, utils.SeverityError, utils.SeverityWarning, utils.SeverityInfo)
+	}
+	return nil
+}
+
+// gate summarizes the findings of the run, writes the summary to --summary-file, and returns the exit
+// code: ExitFindings when some findings reach the --fail-on severity.
+func gate(workloads []handlers.Workload, findings []handlers.Finding) int {
+	failOn, _ := rootCmd.PersistentFlags().GetString("fail-on")
+	summaryFile, _ := rootCmd.PersistentFlags().GetString("summary-file")
+	summary := handlers.SummarizeFindings(workloads, findings, failOn)
+
+	if summaryFile != "" {
+		if err := writeFindingsSummary(summary, summaryFile); err != nil {
+			utils.Error("Failed to write findings summary", zap.String("summaryFile", summaryFile), zap.Error(err))
+			return ExitError
+		}
+	}
+	if summary.Failed {
+		utils.Warn("Findings reached the --fail-on severity", zap.String("failOn", failOn),
+			zap.Int("errors", summary.Severities[utils.SeverityError]),
+			zap.Int("warnings", summary.Severities[utils.SeverityWarning]),
+			zap.Int("infos", summary.Severities[utils.SeverityInfo]))
+		return ExitFindings
+	}
+	return ExitOK
+}
+
+// writeFindingsSummary writes the summary as JSON to a file, or to the standard output with "-".
+func writeFindingsSummary(summary handlers.FindingsSummary, summaryFile string) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if summaryFile == stdoutSummary {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(summaryFile, data, 0644)
+}
@@ -0,0 +1,99 @@
+// cmd/gate_test.go
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s-reporter/handlers"
+	"k8s-reporter/utils"
+)
+
+// setFlag sets a persistent flag of the root command for the duration of the test.
+func setFlag(t *testing.T, name string, value string) {
+	t.Helper()
+	flag := rootCmd.PersistentFlags().Lookup(name)
+	previous, changed := flag.Value.String(), flag.Changed
+	if err := flag.Value.Set(value); err != nil {
+		t.Fatalf("setting --%s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		flag.Value.Set(previous)
+		flag.Changed = changed
+	})
+}
+
+func TestValidateFailOn(t *testing.T) {
+	for _, failOn := range []string{"", utils.SeverityError, utils.SeverityWarning, utils.SeverityInfo} {
+		if err := validateFailOn(failOn); err != nil {
+			t.Errorf("validateFailOn(%q): %v", failOn, err)
+		}
+	}
+	for _, failOn := range []string{"critical", "Error", "warn"} {
+		err := validateFailOn(failOn)
+		if err == nil {
+			t.Errorf("validateFailOn(%q) accepted an invalid severity", failOn)
+		} else if !strings.Contains(err.Error(), "unsupported --fail-on") {
+			t.Errorf("validateFailOn(%q) returned %q", failOn, err)
+		}
+	}
+}
+
+func TestGate(t *testing.T) {
+	findings := []handlers.Finding{
+		{RuleID: "latest-tag", Severity: utils.SeverityWarning, Category: "image"},
+		{RuleID: "no-probes", Severity: utils.SeverityInfo, Category: "security"},
+	}
+
+	for _, test := range []struct {
+		failOn   string
+		findings []handlers.Finding
+		want     int
+	}{
+		{"", findings, ExitOK},
+		{utils.SeverityError, findings, ExitOK},
+		{utils.SeverityWarning, findings, ExitFindings},
+		{utils.SeverityInfo, findings, ExitFindings},
+		{utils.SeverityInfo, nil, ExitOK},
+		{utils.SeverityWarning, findings[1:], ExitOK},
+	} {
+		t.Run(test.failOn, func(t *testing.T) {
+			setFlag(t, "fail-on", test.failOn)
+			if got := gate(nil, test.findings); got != test.want {
+				t.Errorf("gate with --fail-on %q and %d findings returned %d, want %d", test.failOn, len(test.findings), got, test.want)
+			}
+		})
+	}
+}
+
+func TestGateSummaryFile(t *testing.T) {
+	summaryFile := filepath.Join(t.TempDir(), "summary.json")
+	setFlag(t, "fail-on", utils.SeverityError)
+	setFlag(t, "summary-file", summaryFile)
+
+	findings := []handlers.Finding{{RuleID: "privileged", Severity: utils.SeverityError, Category: "security"}}
+	if got := gate(nil, findings); got != ExitFindings {
+		t.Errorf("gate returned %d, want %d", got, ExitFindings)
+	}
+	data, err := os.ReadFile(summaryFile)
+	if err != nil {
+		t.Fatalf("reading the summary: %v", err)
+	}
+	var summary handlers.FindingsSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("decoding the summary: %v", err)
+	}
+	if !summary.Failed || summary.Severities[utils.SeverityError] != 1 {
+		t.Errorf("summary is %+v", summary)
+	}
+
+	// a summary that cannot be written is a failure of k8s-reporter, not a finding
+	setFlag(t, "summary-file", filepath.Join(t.TempDir(), "missing", "summary.json"))
+	if got := gate(nil, findings); got != ExitError {
+		t.Errorf("gate with an unwritable summary returned %d, want %d", got, ExitError)
+	}
+}
@@ -0,0 +1,52 @@
+// cmd/images.go
+
+package cmd
+
+import (
+	"k8s-reporter/handlers"
+	"k8s-reporter/utils"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// imagesCmd represents the images command
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "Export the container image inventory as a CycloneDX BOM",
+	Long: `Export the container image inventory will fetch the Deployments, DaemonSets, StatefulSets, Jobs and CronJobs from a Kubernetes cluster,
+de-duplicate the images of all their containers (including init containers and sidecars) and write them as a CycloneDX JSON bill of materials,
+with their digests and the workloads and namespaces using them.`,
+	RunE: images,
+}
+
+func images(cmd *cobra.Command, args []string) error {
+	bomFile, _ := cmd.Flags().GetString("bom-file")
+	clientset, err := kubernetesClient(cmd)
+	if err != nil {
+		utils.Error("Error building Kubernetes clientset", zap.Error(err))
+		return err
+	}
+
+	utils.Info("Fetching workloads")
+	workloads, err := handlers.FetchWorkloads(clientset)
+	if err != nil {
+		utils.Error("Error fetching workloads", zap.Error(err))
+		return err
+	}
+
+	utils.Info("Writing image inventory", zap.String("fileName", bomFile))
+	if err := utils.WriteCycloneDXBOM(handlers.BuildImageBOM(workloads), bomFile); err != nil {
+		utils.Error("Error writing image inventory", zap.Error(err))
+		return err
+	}
+
+	utils.Info("Image inventory written successfully")
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(imagesCmd)
+	imagesCmd.Flags().String("kubeconfig", "", "Path to the kubeconfig file (optional if environment variable KUBECONFIG is set)")
+	imagesCmd.Flags().String("bom-file", "k8s_images.cdx.json", "Path of the CycloneDX JSON file to write")
+}
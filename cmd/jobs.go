@@ -13,39 +13,36 @@ import (
 // jobsCmd represents the jobs command
 var jobsCmd = &cobra.Command{
 	Use:   "jobs",
-	Short: "Export Jobs to an Excel sheet",
-	Long:  `Export Jobs to an Excel sheet will fetch all the Jobs from a Kubernetes cluster and write their details to an Excel file.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
-		utils.Info("Building Kubernetes clientset")
-		clientset, err := utils.GetKubernetesClient(kubeconfig)
+	Short: "Export Jobs to a report",
+	Long:  `Export Jobs to a report will fetch all the Jobs from a Kubernetes cluster and write their details to a report (an Excel file by default, see --output).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clientset, err := kubernetesClient(cmd)
 		if err != nil {
-			utils.Fatal("Error building Kubernetes clientset", zap.Error(err))
+			utils.Error("Error building Kubernetes clientset", zap.Error(err))
+			return err
 		}
 
 		utils.Info("Fetching Jobs")
 		jobHandler := &handlers.JobHandler{}
 		if err := jobHandler.FetchResources(clientset); err != nil {
-			utils.Fatal("Error fetching Jobs", zap.Error(err))
+			utils.Error("Error fetching Jobs", zap.Error(err))
+			return err
 		}
 
-		excelManager := utils.GetExcelFileManager()
-		if err := excelManager.OpenOrCreateExcelFile("k8s_report.xlsx"); err != nil {
-			utils.Fatal("Failed to open or create Excel file", zap.Error(err))
-		}
-
-		excelFile := excelManager.GetExcelFile()
-		utils.Info("Adding Jobs sheet to Excel file")
-		if err := utils.AddSheetToExcelFile(excelFile, "Jobs", handlers.JobHeaders); err != nil {
-			utils.Fatal("Failed to add sheet to Excel file", zap.Error(err))
+		writer, err := openReportWriter(cmd)
+		if err != nil {
+			utils.Error("Failed to open report writer", zap.Error(err))
+			return err
 		}
 
-		utils.Info("Writing Jobs data to Excel sheet")
-		if err := jobHandler.WriteExcel(clientset, excelFile, "Jobs"); err != nil {
-			utils.Fatal("Error writing to Excel", zap.Error(err))
+		utils.Info("Writing Jobs data to report")
+		if err := jobHandler.WriteReport(writer, "Jobs"); err != nil {
+			utils.Error("Error writing report", zap.Error(err))
+			return err
 		}
 
-		utils.Info("Jobs data written to Excel file successfully.")
+		utils.Info("Jobs data written to report successfully.")
+		return nil
 	},
 }
 
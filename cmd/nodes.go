@@ -0,0 +1,54 @@
+// cmd/nodes.go
+
+package cmd
+
+import (
+	"k8s-reporter/handlers"
+	"k8s-reporter/utils"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// nodesCmd represents the nodes command
+var nodesCmd = &cobra.Command{
+	Use:   "nodes",
+	Short: "Export Nodes to a report",
+	Long: `Export Nodes to a report will fetch all the Nodes from a Kubernetes cluster and write their details to a report (an Excel file by default, see --output):
+capacity, allocatable, the requests and limits of the pods scheduled on them and the percentage of allocatable they commit,
+taints, zone, instance type, kubelet version, conditions and pod count. The last row sums the whole cluster.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clientset, err := kubernetesClient(cmd)
+		if err != nil {
+			utils.Error("Error building Kubernetes clientset", zap.Error(err))
+			return err
+		}
+
+		utils.Info("Fetching Nodes")
+		nodeHandler := &handlers.NodeHandler{}
+		if err := nodeHandler.FetchResources(clientset); err != nil {
+			utils.Error("Error fetching Nodes", zap.Error(err))
+			return err
+		}
+
+		writer, err := openReportWriter(cmd)
+		if err != nil {
+			utils.Error("Failed to open report writer", zap.Error(err))
+			return err
+		}
+
+		utils.Info("Writing Nodes data to report")
+		if err := nodeHandler.WriteReport(writer, "Nodes"); err != nil {
+			utils.Error("Error writing report", zap.Error(err))
+			return err
+		}
+
+		utils.Info("Nodes data written to report successfully.")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nodesCmd)
+	nodesCmd.Flags().String("kubeconfig", "", "Path to the kubeconfig file (optional if environment variable KUBECONFIG is set)")
+}
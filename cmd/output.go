@@ -0,0 +1,37 @@
+// cmd/output.go
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s-reporter/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// validateOutputFormat checks the --output flag, honouring the deprecated --format alias.
+func validateOutputFormat(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	if flags.Changed("format") && !flags.Changed("output") {
+		format, _ := flags.GetString("format")
+		if err := flags.Set("output", format); err != nil {
+			return err
+		}
+	}
+
+	output, _ := flags.GetString("output")
+	for _, format := range utils.OutputFormats {
+		if output == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output %q, must be one of: %s", output, strings.Join(utils.OutputFormats, ", "))
+}
+
+// openReportWriter returns the report writer for the format selected with --output.
+func openReportWriter(cmd *cobra.Command) (utils.ReportWriter, error) {
+	output, _ := cmd.Flags().GetString("output")
+	return utils.OpenReportWriter(output)
+}
@@ -0,0 +1,53 @@
+// cmd/pods.go
+
+package cmd
+
+import (
+	"k8s-reporter/handlers"
+	"k8s-reporter/utils"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// podsCmd represents the pods command
+var podsCmd = &cobra.Command{
+	Use:   "pods",
+	Short: "Export Pods to a report",
+	Long: `Export Pods to a report will fetch all the Pods from a Kubernetes cluster and write their live status to a report (an Excel file by default, see --output):
+phase, node, pod IP, readiness, restart counts, last termination reason, start time, age and the workload owning each pod.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clientset, err := kubernetesClient(cmd)
+		if err != nil {
+			utils.Error("Error building Kubernetes clientset", zap.Error(err))
+			return err
+		}
+
+		utils.Info("Fetching Pods")
+		podHandler := &handlers.PodHandler{}
+		if err := podHandler.FetchResources(clientset); err != nil {
+			utils.Error("Error fetching Pods", zap.Error(err))
+			return err
+		}
+
+		writer, err := openReportWriter(cmd)
+		if err != nil {
+			utils.Error("Failed to open report writer", zap.Error(err))
+			return err
+		}
+
+		utils.Info("Writing Pods data to report")
+		if err := podHandler.WriteReport(writer, "Pods"); err != nil {
+			utils.Error("Error writing report", zap.Error(err))
+			return err
+		}
+
+		utils.Info("Pods data written to report successfully.")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(podsCmd)
+	podsCmd.Flags().String("kubeconfig", "", "Path to the kubeconfig file (optional if environment variable KUBECONFIG is set)")
+}
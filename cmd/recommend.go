@@ -0,0 +1,81 @@
+// cmd/recommend.go
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"k8s-reporter/handlers"
+	"k8s-reporter/utils"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// recommendCmd represents the recommend command
+var recommendCmd = &cobra.Command{
+	Use:   "recommend",
+	Short: "Export right-sizing recommendations to a report",
+	Long: `Export right-sizing recommendations to a report will fetch the Deployments, DaemonSets, StatefulSets, Jobs and CronJobs from a Kubernetes cluster,
+query the p95/p99 CPU usage and the peak memory usage of their containers over a window from a Prometheus-compatible HTTP API,
+and write the recommended requests and limits next to the current ones, with the estimated savings, to a report (an Excel file by default, see --output).`,
+	RunE: recommend,
+}
+
+func recommend(cmd *cobra.Command, args []string) error {
+	prometheusURL, _ := cmd.Flags().GetString("prometheus-url")
+	window, _ := cmd.Flags().GetDuration("window")
+	headroom, _ := cmd.Flags().GetFloat64("headroom")
+	if window < time.Hour {
+		return fmt.Errorf("invalid --window %s: must be at least 1h", window)
+	}
+	if headroom < 0 {
+		return fmt.Errorf("invalid --headroom %g: must not be negative", headroom)
+	}
+	prometheus, err := utils.NewPrometheusClient(prometheusURL)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := kubernetesClient(cmd)
+	if err != nil {
+		utils.Error("Error building Kubernetes clientset", zap.Error(err))
+		return err
+	}
+
+	utils.Info("Fetching workloads and their usage", zap.String("prometheusURL", prometheusURL), zap.Duration("window", window))
+	recommendationHandler := &handlers.RecommendationHandler{
+		Prometheus: prometheus,
+		Window:     window,
+		Headroom:   headroom,
+	}
+	if err := recommendationHandler.FetchResources(clientset); err != nil {
+		utils.Error("Error fetching workloads and their usage", zap.Error(err))
+		return err
+	}
+
+	writer, err := openReportWriter(cmd)
+	if err != nil {
+		utils.Error("Failed to open report writer", zap.Error(err))
+		return err
+	}
+
+	utils.Info("Writing Recommendations data to report")
+	if err := recommendationHandler.WriteReport(writer, "Recommendations"); err != nil {
+		utils.Error("Error writing report", zap.Error(err))
+		return err
+	}
+
+	utils.Info("Recommendations data written to report successfully")
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(recommendCmd)
+	recommendCmd.Flags().String("kubeconfig", "", "Path to the kubeconfig file (optional if environment variable KUBECONFIG is set)")
+	recommendCmd.Flags().String("prometheus-url", "", "URL of the Prometheus-compatible HTTP API to read the container usage from, e.g. http://prometheus.monitoring:9090")
+	recommendCmd.Flags().Duration("window", 7*24*time.Hour, "How far back the container usage is looked at")
+	recommendCmd.Flags().Float64("headroom", 15, "Percentage added on top of the observed usage to compute the recommendations")
+	recommendCmd.MarkFlagRequired("prometheus-url")
+}
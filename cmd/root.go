@@ -3,8 +3,10 @@
 package cmd
 
 import (
+	"errors"
+
+	"k8s-reporter/handlers"
 	"k8s-reporter/utils"
-	"os"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -13,15 +15,99 @@ import (
 var rootCmd = &cobra.Command{
 	Use:   "k8s-reporter",
 	Short: "k8s-reporter is a CLI for creating a report about Kubernetes objects",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		teamLabels, _ := cmd.Flags().GetStringSlice("team-label")
+		utils.SetTeamLabels(teamLabels)
+		helmCharts, _ := cmd.Flags().GetStringSlice("helm-chart")
+		valuesFiles, _ := cmd.Flags().GetStringSlice("values")
+		if err := validateValues(helmCharts, valuesFiles); err != nil {
+			return err
+		}
+		if withUsage, _ := cmd.Flags().GetBool("with-usage"); withUsage {
+			if fromManifests(cmd) {
+				return errors.New("--with-usage reads the usage of a live cluster, it cannot be used with --from-files, --helm-chart or --kustomize")
+			}
+			kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+			metricsClient, err := utils.GetMetricsClient(kubeconfig)
+			if err != nil {
+				utils.Error("Failed to create metrics client", zap.Error(err))
+				return err
+			}
+			utils.EnableUsage(metricsClient)
+		}
+		if pricing, _ := cmd.Flags().GetString("pricing"); pricing != "" {
+			prices, err := utils.LoadPriceTable(pricing)
+			if err != nil {
+				utils.Error("Failed to load price table", zap.String("pricing", pricing), zap.Error(err))
+				return err
+			}
+			utils.SetPriceTable(prices)
+		}
+		if vulnDB, _ := cmd.Flags().GetString("vuln-db"); vulnDB != "" {
+			db, err := utils.LoadVulnerabilityDB(vulnDB)
+			if err != nil {
+				utils.Error("Failed to load vulnerability database", zap.String("vulnDB", vulnDB), zap.Error(err))
+				return err
+			}
+			utils.SetVulnerabilityDB(db)
+		}
+		if rulesFile, _ := cmd.Flags().GetString("rules"); rulesFile != "" {
+			rules, err := utils.LoadRules(rulesFile)
+			if err != nil {
+				utils.Error("Failed to load rules", zap.String("rules", rulesFile), zap.Error(err))
+				return err
+			}
+			utils.SetRules(rules)
+		}
+		failOn, _ := cmd.Flags().GetString("fail-on")
+		if err := validateFailOn(failOn); err != nil {
+			return err
+		}
+		return validateOutputFormat(cmd)
+	},
 }
 
-func Execute() {
+// Execute runs the command line, writes the summary sections and checks the findings of the run
+// against --fail-on. It returns the exit code: ExitOK, ExitError or ExitFindings.
+func Execute() int {
 	if err := rootCmd.Execute(); err != nil {
 		utils.Error("Execution failed", zap.Error(err))
-		os.Exit(1)
+		return ExitError
+	}
+	workloads := handlers.Workloads()
+	findings, err := handlers.CheckWorkloads(workloads)
+	if err != nil {
+		utils.Error("Failed to check workloads", zap.Error(err))
+		return ExitError
+	}
+	if err := writeSummarySections(workloads, findings); err != nil {
+		utils.Error("Failed to write summary sections", zap.Error(err))
+		return ExitError
+	}
+	if sarifFile, _ := rootCmd.PersistentFlags().GetString("sarif"); sarifFile != "" {
+		if err := utils.WriteSARIFLog(handlers.BuildSARIF(findings), sarifFile); err != nil {
+			utils.Error("Failed to write SARIF log", zap.String("sarifFile", sarifFile), zap.Error(err))
+			return ExitError
+		}
 	}
+	return gate(workloads, findings)
 }
 
 func init() {
 	rootCmd.PersistentFlags().String("kubeconfig", "", "Path to the kubeconfig file")
+	rootCmd.PersistentFlags().StringSlice("from-files", nil, "Report on manifests instead of a live cluster: YAML or JSON files, directories or - for the standard input (multi-document files and List dumps are supported)")
+	rootCmd.PersistentFlags().StringSlice("helm-chart", nil, "Report on the manifests rendered from a local Helm chart (a directory or a .tgz archive) instead of a live cluster")
+	rootCmd.PersistentFlags().StringSlice("values", nil, "Values files of --helm-chart, merged in order like helm template --values (a single --helm-chart only)")
+	rootCmd.PersistentFlags().StringSlice("kustomize", nil, "Report on the manifests built from a local kustomization directory instead of a live cluster")
+	rootCmd.PersistentFlags().StringP("output", "o", utils.OutputXLSX, "Report format: xlsx, csv, json, yaml or ndjson (written to k8s_report.<format>, or one k8s_report_<resource>.csv per resource kind)")
+	rootCmd.PersistentFlags().StringSlice("team-label", []string{"team"}, "Label keys looked up, in order, on workloads and their namespace to fill the team in the Owner column")
+	rootCmd.PersistentFlags().Bool("with-usage", false, "Report the current CPU and memory usage from the metrics.k8s.io API (requires metrics-server)")
+	rootCmd.PersistentFlags().String("pricing", "", "Path to a YAML price table ($/vCPU-hour and $/GiB-hour, optionally per namespace or node instance type) to report monthly costs")
+	rootCmd.PersistentFlags().String("vuln-db", "", "Path to a local JSON or SQLite vulnerability database (known vulnerable and banned images, or Trivy JSON reports) to match images against, offline")
+	rootCmd.PersistentFlags().String("rules", "", "Path to a YAML file of custom rules (CEL expressions over every workload) reported as findings")
+	rootCmd.PersistentFlags().String("fail-on", "", "Exit with code 2 when a finding is at least this severe: error, warning or info (exit code 1 is kept for failures of k8s-reporter itself)")
+	rootCmd.PersistentFlags().String("summary-file", "", "Path of a JSON file to write the finding counts per severity, category and rule to, or - for the standard output")
+	rootCmd.PersistentFlags().String("sarif", "", "Path of a SARIF 2.1.0 file to write the findings to, for code-scanning dashboards")
+	rootCmd.PersistentFlags().String("format", utils.OutputXLSX, "Report format")
+	rootCmd.PersistentFlags().MarkDeprecated("format", "use --output instead")
 }
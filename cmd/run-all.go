@@ -12,17 +12,18 @@ import (
 var runCmd = &cobra.Command{
 	Use:   "run-all",
 	Short: "Run all resource commands",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		utils.Info("Running all resources...")
-		resources := []string{"deployments", "daemonsets", "statefulsets", "jobs"}
+		resources := []string{"deployments", "daemonsets", "statefulsets", "jobs", "cronjobs", "pods", "nodes"}
 		for _, resource := range resources {
 			utils.Info("Running resource command", zap.String("resource", resource))
 			rootCmd.SetArgs([]string{resource})
 			if err := rootCmd.Execute(); err != nil {
 				utils.Error("Failed to execute resource command", zap.String("resource", resource), zap.Error(err))
-				break // Stop executing further commands after an error
+				return err // Stop executing further commands after an error
 			}
 		}
+		return nil
 	},
 }
 
@@ -0,0 +1,65 @@
+// cmd/summary.go
+
+package cmd
+
+import (
+	"k8s-reporter/handlers"
+	"k8s-reporter/utils"
+)
+
+// writeSummarySections writes the sections aggregating the workloads reported by every
+// resource command of this run, e.g. all the commands of run-all, and their findings.
+func writeSummarySections(workloads []handlers.Workload, findings []handlers.Finding) error {
+	if len(workloads) == 0 {
+		return nil
+	}
+
+	output, _ := rootCmd.PersistentFlags().GetString("output")
+	writer, err := utils.OpenReportWriter(output)
+	if err != nil {
+		return err
+	}
+
+	utils.Info("Writing Totals to report")
+	if err := handlers.WriteTotals(writer, "Totals", workloads); err != nil {
+		return err
+	}
+
+	utils.Info("Writing Namespaces summary to report")
+	if err := handlers.WriteNamespaceSummary(writer, "Namespaces", workloads); err != nil {
+		return err
+	}
+
+	utils.Info("Writing Images to report")
+	if err := handlers.WriteImages(writer, "Images", workloads); err != nil {
+		return err
+	}
+
+	utils.Info("Writing Security audit to report")
+	if err := handlers.WriteSecurity(writer, "Security", workloads); err != nil {
+		return err
+	}
+
+	utils.Info("Writing Pod Security evaluation to report")
+	if err := handlers.WritePodSecurity(writer, "Pod Security", "Namespace Pod Security", workloads); err != nil {
+		return err
+	}
+
+	utils.Info("Writing Findings to report")
+	if err := handlers.WriteFindings(writer, "Findings", findings); err != nil {
+		return err
+	}
+
+	if utils.UsageEnabled() {
+		utils.Info("Writing Usage to report")
+		if err := handlers.WriteUsage(writer, "Usage", workloads); err != nil {
+			return err
+		}
+	}
+
+	if prices := utils.GetPriceTable(); prices != nil {
+		utils.Info("Writing Costs to report")
+		return handlers.WriteCosts(writer, "Costs", workloads, prices)
+	}
+	return nil
+}
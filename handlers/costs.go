@@ -0,0 +1,158 @@
+// handlers/costs.go
+
+package handlers
+
+import (
+	"k8s-reporter/utils"
+	"math"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var CostsHeaders = []string{
+	"Kind",
+	"Namespace",
+	"Name",
+	"Replicas",
+	"Total CPU Requests (m)",
+	"Total Memory Requests (MiB)",
+	"Monthly CPU Cost",
+	"Monthly Memory Cost",
+	"Monthly Cost (Requests)",
+	"CPU Usage (m)",
+	"Memory Usage (MiB)",
+	"Monthly Cost (Usage)",
+}
+
+// monthlyCostPrefix starts the headers of the monthly cost columns of every section.
+const monthlyCostPrefix = "Monthly "
+
+// currencyHeaders returns the headers of a section with the currency of the price table in its monthly
+// cost columns, e.g. "Monthly Cost (Requests, EUR)", or the headers as they are without a price table.
+func currencyHeaders(headers []string, prices *utils.PriceTable) []string {
+	if prices == nil {
+		return headers
+	}
+	result := make([]string, len(headers))
+	for i, header := range headers {
+		switch {
+		case !strings.HasPrefix(header, monthlyCostPrefix):
+			result[i] = header
+		case strings.HasSuffix(header, ")"):
+			result[i] = strings.TrimSuffix(header, ")") + ", " + prices.Currency + ")"
+		default:
+			result[i] = header + " (" + prices.Currency + ")"
+		}
+	}
+	return result
+}
+
+// workloadCost is the monthly cost of a workload, or the sum of the costs of several workloads.
+type workloadCost struct {
+	cpu    float64
+	memory float64
+	// usage is the cost of the current usage, nil when usage is not reported.
+	usage *float64
+}
+
+func (c workloadCost) requests() float64 {
+	return roundCents(c.cpu + c.memory)
+}
+
+func (c *workloadCost) add(other workloadCost) {
+	c.cpu = roundCents(c.cpu + other.cpu)
+	c.memory = roundCents(c.memory + other.memory)
+	if other.usage != nil {
+		usage := *other.usage
+		if c.usage != nil {
+			usage = roundCents(usage + *c.usage)
+		}
+		c.usage = &usage
+	}
+}
+
+func (c workloadCost) usageCell() interface{} {
+	if c.usage == nil {
+		return nil
+	}
+	return *c.usage
+}
+
+// costOf returns the monthly cost of the total requests and of the current usage of a workload,
+// at the rates of its namespace.
+func costOf(prices *utils.PriceTable, workload Workload) workloadCost {
+	rates := prices.NamespaceRates(workload.Namespace)
+	cost := workloadCost{
+		cpu:    rates.MonthlyCost(workload.TotalCPURequests(), resource.Quantity{}),
+		memory: rates.MonthlyCost(resource.Quantity{}, workload.TotalMemoryRequests()),
+	}
+	if workload.Usage != nil {
+		usage := rates.MonthlyCost(workload.Usage.CPU, workload.Usage.Memory)
+		cost.usage = &usage
+	}
+	return cost
+}
+
+// roundCents rounds an amount to the cent.
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// costsRecord builds the report row of the monthly cost of a single workload.
+func costsRecord(workload Workload, cost workloadCost) []interface{} {
+	var cpuUsage, memoryUsage interface{}
+	if workload.Usage != nil {
+		cpuUsage = utils.CPUMillicores(workload.Usage.CPU)
+		memoryUsage = utils.MemoryMiB(workload.Usage.Memory)
+	}
+	return []interface{}{
+		workload.Kind,
+		workload.Namespace,
+		workload.Name,
+		workload.Replicas,
+		utils.CPUMillicores(workload.TotalCPURequests()),
+		utils.MemoryMiB(workload.TotalMemoryRequests()),
+		cost.cpu,
+		cost.memory,
+		cost.requests(),
+		cpuUsage,
+		memoryUsage,
+		cost.usageCell(),
+	}
+}
+
+// WriteCosts writes the monthly cost of the given workloads, computed from their total requests and,
+// with --with-usage, from their current usage, followed by a cluster-wide row named "All".
+func WriteCosts(writer utils.ReportWriter, section string, workloads []Workload, prices *utils.PriceTable) error {
+	utils.Info("Writing Costs to report", zap.String("section", section), zap.String("currency", prices.Currency))
+	if err := writer.AddSection(section, currencyHeaders(CostsHeaders, prices)); err != nil {
+		utils.Error("Failed to add Costs section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+
+	var total workloadCost
+	for _, workload := range workloads {
+		cost := costOf(prices, workload)
+		total.add(cost)
+		if err := writer.WriteRow(section, costsRecord(workload, cost)); err != nil {
+			utils.Error("Failed to write report row for workload costs", zap.String("kind", workload.Kind), zap.String("name", workload.Name), zap.Error(err))
+			return err
+		}
+	}
+
+	if err := writer.WriteRow(section, []interface{}{
+		allValue, nil, nil, nil, nil, nil,
+		total.cpu,
+		total.memory,
+		total.requests(),
+		nil, nil,
+		total.usageCell(),
+	}); err != nil {
+		utils.Error("Failed to write cluster-wide report row for Costs", zap.Error(err))
+		return err
+	}
+	utils.Info("Successfully written Costs to report", zap.String("section", section))
+	return nil
+}
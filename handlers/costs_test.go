@@ -0,0 +1,122 @@
+// handlers/costs_test.go
+
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s-reporter/utils"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func TestWriteCostsCountsCronJobUsageOnce(t *testing.T) {
+	resetWorkloads(t)
+	isController := true
+	requests := v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("1Gi")}
+	template := v1.PodTemplateSpec{
+		Spec: v1.PodSpec{Containers: []v1.Container{{Name: "main", Image: "busybox:1.36", Resources: v1.ResourceRequirements{Requests: requests}}}},
+	}
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "batch", UID: "cronjob-uid"},
+		Spec: batchv1.CronJobSpec{
+			Schedule:    "0 * * * *",
+			JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: template}},
+		},
+		Status: batchv1.CronJobStatus{Active: []v1.ObjectReference{{Kind: "Job", Namespace: "batch", Name: "report-28000000"}}},
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "report-28000000",
+			Namespace: "batch",
+			UID:       "job-uid",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "batch/v1", Kind: "CronJob", Name: "report", UID: "cronjob-uid", Controller: &isController,
+			}},
+		},
+		Spec: batchv1.JobSpec{Template: template},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "report-28000000-x7k2p",
+			Namespace: "batch",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "batch/v1", Kind: "Job", Name: "report-28000000", UID: "job-uid", Controller: &isController,
+			}},
+		},
+		Spec: template.Spec,
+	}
+	clientset := fake.NewSimpleClientset(cronJob, job, pod)
+
+	metricsClient := metricsfake.NewSimpleClientset()
+	podMetrics := &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		Containers: []metricsv1beta1.ContainerMetrics{{
+			Name:  "main",
+			Usage: requests,
+		}},
+	}
+	// The fake metrics clientset lists PodMetrics under the "pods" resource.
+	if err := metricsClient.Tracker().Create(metricsv1beta1.SchemeGroupVersion.WithResource("pods"), podMetrics, pod.Namespace); err != nil {
+		t.Fatalf("adding pod metrics: %v", err)
+	}
+	utils.EnableUsage(metricsClient)
+	t.Cleanup(utils.DisableUsage)
+
+	// The Job row has the usage of its pod, but only the CronJob is summed in the Costs.
+	if usage := utils.GetWorkloadUsage(clientset, "Job", job.Namespace, job.Name); usage == nil || usage.Pods != 1 {
+		t.Errorf("usage of the Job is %+v, want the usage of its pod", usage)
+	}
+
+	writer := &recordingWriter{}
+	for _, handler := range []ResourceHandler{&JobHandler{}, &CronJobHandler{}} {
+		if err := handler.FetchResources(clientset); err != nil {
+			t.Fatalf("FetchResources: %v", err)
+		}
+		if err := handler.WriteReport(writer, "Workloads"); err != nil {
+			t.Fatalf("WriteReport: %v", err)
+		}
+	}
+	prices := &utils.PriceTable{Currency: "USD", Rates: utils.Rates{CPUHourly: 0.04, MemoryGiBHourly: 0.005}}
+	if err := WriteCosts(writer, "Costs", Workloads(), prices); err != nil {
+		t.Fatalf("WriteCosts: %v", err)
+	}
+
+	rows := writer.rows["Costs"]
+	if len(rows) != 2 {
+		t.Fatalf("Costs section has %d rows, want the CronJob and All", len(rows))
+	}
+	// the pod requests and uses 0.5 vCPU at 0.04 and 1 GiB at 0.005 an hour, over 730 hours
+	want := 18.25
+	all := rows[len(rows)-1]
+	if all[0] != allValue {
+		t.Fatalf("last row is %v, want the All row", all)
+	}
+	if got := all[len(all)-1]; got != want {
+		t.Errorf("monthly usage cost of All is %v, want %v", got, want)
+	}
+	if got := all[8]; got != want {
+		t.Errorf("monthly requests cost of All is %v, want %v", got, want)
+	}
+}
+
+func TestCurrencyHeaders(t *testing.T) {
+	headers := []string{"Name", "Monthly CPU Cost", "Monthly Cost (Requests)"}
+	if got := currencyHeaders(headers, nil); !reflect.DeepEqual(got, headers) {
+		t.Errorf("headers without a price table are %v, want %v", got, headers)
+	}
+	want := []string{"Name", "Monthly CPU Cost (EUR)", "Monthly Cost (Requests, EUR)"}
+	if got := currencyHeaders(headers, &utils.PriceTable{Currency: "EUR"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("headers are %v, want %v", got, want)
+	}
+	if headers[1] != "Monthly CPU Cost" {
+		t.Errorf("currencyHeaders changed the headers it was given: %v", headers)
+	}
+}
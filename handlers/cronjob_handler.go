@@ -0,0 +1,174 @@
+// handlers/cronjob_handler.go
+
+package handlers
+
+import (
+	"context"
+	"k8s-reporter/utils"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CronJobHandler is a struct that implements the ResourceHandler interface
+// for Kubernetes CronJobs.
+type CronJobHandler struct {
+	CronJobs  []batchv1.CronJob
+	clientset kubernetes.Interface
+}
+
+var CronJobHeaders = []string{
+	"Name",
+	"Namespace",
+	"Schedule",
+	"Time Zone",
+	"Suspend",
+	"Concurrency Policy",
+	"Active Jobs",
+	"Last Schedule Time",
+	"Last Successful Time",
+	"Successful Jobs History Limit",
+	"Failed Jobs History Limit",
+	"Node Selector",
+	"CPU Requests (m)",
+	"Memory Requests (MiB)",
+	"CPU Limits (m)",
+	"Memory Limits (MiB)",
+	"CPU Diff (m)",
+	"Memory Diff (MiB)",
+	"Memory diff > 2 x Request",
+	"Image Versions",
+	"QoS Class",
+	"Owner",
+	"LimitRange Defaults Applied",
+	"LimitRange Violations",
+	"Init CPU Requests (m)",
+	"Init Memory Requests (MiB)",
+	"Sidecar CPU Requests (m)",
+	"Sidecar Memory Requests (MiB)",
+	"Overhead CPU (m)",
+	"Overhead Memory (MiB)",
+	"Image Findings",
+	"Security Findings",
+}
+
+// FetchResources fetches all CronJobs across all namespaces and stores them.
+func (c *CronJobHandler) FetchResources(clientset kubernetes.Interface) error {
+	utils.Info("Fetching CronJobs from Kubernetes cluster")
+	cronJobs, err := clientset.BatchV1().CronJobs("").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		utils.Error("Failed to fetch CronJobs", zap.Error(err))
+		return err
+	}
+	c.CronJobs = cronJobs.Items
+	c.clientset = clientset
+	utils.Info("Fetched CronJobs", zap.Int("count", len(c.CronJobs)))
+	return nil
+}
+
+// workload builds the common view of a single CronJob. The CronJob stands for the Jobs it spawned,
+// so it runs the pods of its active runs: the parallelism of its Job template for each of them.
+func (c *CronJobHandler) workload(cronJob batchv1.CronJob) Workload {
+	parallelism := int32(1)
+	if cronJob.Spec.JobTemplate.Spec.Parallelism != nil {
+		parallelism = *cronJob.Spec.JobTemplate.Spec.Parallelism
+	}
+	return Workload{
+		Kind:            "CronJob",
+		Name:            cronJob.Name,
+		Namespace:       cronJob.Namespace,
+		Object:          &cronJob,
+		Replicas:        int32(len(cronJob.Status.Active)) * parallelism,
+		Resources:       utils.ExtractResources(c.clientset, cronJob.Spec.JobTemplate.Spec.Template.Spec, cronJob.Namespace),
+		PodSpec:         cronJob.Spec.JobTemplate.Spec.Template.Spec,
+		PodAnnotations:  cronJob.Spec.JobTemplate.Spec.Template.Annotations,
+		NamespaceLabels: utils.GetNamespaceLabels(c.clientset, cronJob.Namespace),
+		Usage:           utils.GetWorkloadUsage(c.clientset, "CronJob", cronJob.Namespace, cronJob.Name),
+	}
+}
+
+// record builds the report row of a single CronJob.
+func (c *CronJobHandler) record(cronJob batchv1.CronJob, workload Workload) []interface{} {
+	podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	resources := workload.Resources
+	imageVersions := utils.ExtractImageVersions(podSpec)
+
+	var timeZone string
+	if cronJob.Spec.TimeZone != nil {
+		timeZone = *cronJob.Spec.TimeZone
+	}
+
+	return []interface{}{
+		cronJob.Name,
+		cronJob.Namespace,
+		cronJob.Spec.Schedule,
+		timeZone,
+		cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+		string(cronJob.Spec.ConcurrencyPolicy),
+		len(cronJob.Status.Active),
+		formatTime(cronJob.Status.LastScheduleTime),
+		formatTime(cronJob.Status.LastSuccessfulTime),
+		int32Value(cronJob.Spec.SuccessfulJobsHistoryLimit),
+		int32Value(cronJob.Spec.FailedJobsHistoryLimit),
+		utils.FormatNodeSelector(podSpec.NodeSelector),
+		utils.CPUMillicores(resources.CPURequests),
+		utils.MemoryMiB(resources.MemoryRequests),
+		utils.CPUMillicores(resources.CPULimits),
+		utils.MemoryMiB(resources.MemoryLimits),
+		utils.CPUMillicores(resources.CPUDiff()),
+		utils.MemoryMiB(resources.MemoryDiff()),
+		resources.MemoryReadiness(),
+		imageVersions,
+		resources.QoSClass,
+		utils.ResolveOwner(c.clientset, cronJob.ObjectMeta),
+		resources.DefaultsApplied,
+		strings.Join(resources.LimitRangeViolations, "; "),
+		utils.CPUMillicores(resources.InitCPURequests),
+		utils.MemoryMiB(resources.InitMemoryRequests),
+		utils.CPUMillicores(resources.SidecarCPURequests),
+		utils.MemoryMiB(resources.SidecarMemoryRequests),
+		utils.CPUMillicores(resources.OverheadCPU),
+		utils.MemoryMiB(resources.OverheadMemory),
+		imageFindingsCell(workload),
+		securityFindingsCell(workload),
+	}
+}
+
+// WriteReport writes the information of the fetched CronJobs to a report section.
+func (c *CronJobHandler) WriteReport(writer utils.ReportWriter, section string) error {
+	utils.Info("Writing CronJobs data to report", zap.String("section", section))
+	if err := writer.AddSection(section, CronJobHeaders); err != nil {
+		utils.Error("Failed to add CronJobs section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+	for _, cronJob := range c.CronJobs {
+		workload := c.workload(cronJob)
+		RecordWorkload(workload)
+		if err := writer.WriteRow(section, c.record(cronJob, workload)); err != nil {
+			utils.Error("Failed to write report row for CronJob", zap.String("cronJobName", cronJob.Name), zap.Error(err))
+			return err
+		}
+	}
+	utils.Info("Successfully written CronJob data to report", zap.String("section", section))
+	return nil
+}
+
+// formatTime formats an optional timestamp as RFC 3339, or returns nil for an empty cell.
+func formatTime(t *metav1.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// int32Value returns the value of an optional integer, or nil for an empty cell.
+func int32Value(value *int32) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
@@ -4,11 +4,10 @@ package handlers
 
 import (
 	"context"
-	"strconv"
+	"strings"
 
 	"k8s-reporter/utils"
 
-	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
 	v1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -19,6 +18,7 @@ import (
 // for Kubernetes DaemonSets.
 type DaemonSetHandler struct {
 	DaemonSets []v1.DaemonSet
+	clientset  kubernetes.Interface
 }
 
 var DaemonSetHeaders = []string{
@@ -30,20 +30,34 @@ var DaemonSetHeaders = []string{
 	"Up-to-date",
 	"Available",
 	"Node Selector",
-	"CPU Requests",
-	"Memory Requests",
-	"CPU Limits",
-	"Memory Limits",
-	"CPU Diff",
-	"Memory Diff",
+	"CPU Requests (m)",
+	"Memory Requests (MiB)",
+	"CPU Limits (m)",
+	"Memory Limits (MiB)",
+	"CPU Diff (m)",
+	"Memory Diff (MiB)",
 	"Memory diff > 2 x Request",
 	"Image Versions",
 	"QoS Class",
 	"Owner",
+	"LimitRange Defaults Applied",
+	"LimitRange Violations",
+	"Init CPU Requests (m)",
+	"Init Memory Requests (MiB)",
+	"Sidecar CPU Requests (m)",
+	"Sidecar Memory Requests (MiB)",
+	"Overhead CPU (m)",
+	"Overhead Memory (MiB)",
+	"Total CPU Requests (m)",
+	"Total Memory Requests (MiB)",
+	"Total CPU Limits (m)",
+	"Total Memory Limits (MiB)",
+	"Image Findings",
+	"Security Findings",
 }
 
 // FetchResources fetches all DaemonSets across all namespaces and stores them.
-func (d *DaemonSetHandler) FetchResources(clientset *kubernetes.Clientset) error {
+func (d *DaemonSetHandler) FetchResources(clientset kubernetes.Interface) error {
 	utils.Info("Fetching DaemonSets from Kubernetes cluster")
 	daemonSets, err := clientset.AppsV1().DaemonSets("").List(context.Background(), metav1.ListOptions{})
 	if err != nil {
@@ -51,56 +65,86 @@ func (d *DaemonSetHandler) FetchResources(clientset *kubernetes.Clientset) error
 		return err
 	}
 	d.DaemonSets = daemonSets.Items
+	d.clientset = clientset
 	utils.Info("Fetched DaemonSets", zap.Int("count", len(d.DaemonSets)))
 	return nil
 }
 
-// WriteExcel writes the information of the fetched DaemonSets to an Excel sheet.
-func (d *DaemonSetHandler) WriteExcel(clientset *kubernetes.Clientset, f *excelize.File, sheetName string) error {
-	utils.Info("Writing DaemonSets data to Excel sheet", zap.String("sheetName", sheetName))
-	// Starting from the second row, since the first row is for headers
-	rowIndex := 2
-	for _, ds := range d.DaemonSets {
-		name := ds.Name
-		namespace := ds.Namespace
-		podSpec := ds.Spec.Template.Spec
-		cpuRequests, memoryRequests, cpuLimits, memoryLimits, cpuDiff, memoryDiff, memoryReadiness, qosClass := utils.ExtractResources(clientset, podSpec, namespace)
-		imageVersions := utils.ExtractImageVersions(podSpec)
-		// qosClass := utils.DetermineQoSClass(podSpec)
+// workload builds the common view of a single DaemonSet, running as many pods as its desired number of scheduled pods.
+func (d *DaemonSetHandler) workload(ds v1.DaemonSet) Workload {
+	return Workload{
+		Kind:            "DaemonSet",
+		Name:            ds.Name,
+		Namespace:       ds.Namespace,
+		Object:          &ds,
+		Replicas:        ds.Status.DesiredNumberScheduled,
+		Resources:       utils.ExtractResources(d.clientset, ds.Spec.Template.Spec, ds.Namespace),
+		PodSpec:         ds.Spec.Template.Spec,
+		PodAnnotations:  ds.Spec.Template.Annotations,
+		NamespaceLabels: utils.GetNamespaceLabels(d.clientset, ds.Namespace),
+		Usage:           utils.GetWorkloadUsage(d.clientset, "DaemonSet", ds.Namespace, ds.Name),
+	}
+}
 
-		record := []interface{}{
-			name,
-			namespace,
-			strconv.Itoa(int(ds.Status.DesiredNumberScheduled)),
-			strconv.Itoa(int(ds.Status.CurrentNumberScheduled)),
-			strconv.Itoa(int(ds.Status.NumberReady)),
-			strconv.Itoa(int(ds.Status.UpdatedNumberScheduled)),
-			strconv.Itoa(int(ds.Status.NumberAvailable)),
-			utils.FormatNodeSelector(podSpec.NodeSelector),
-			cpuRequests,
-			memoryRequests,
-			cpuLimits,
-			memoryLimits,
-			cpuDiff,
-			memoryDiff,
-			memoryReadiness,
-			imageVersions,
-			qosClass,
-		}
+// record builds the report row of a single DaemonSet.
+func (d *DaemonSetHandler) record(ds v1.DaemonSet, workload Workload) []interface{} {
+	name := ds.Name
+	namespace := ds.Namespace
+	podSpec := ds.Spec.Template.Spec
+	resources := workload.Resources
+	imageVersions := utils.ExtractImageVersions(podSpec)
+
+	return []interface{}{
+		name,
+		namespace,
+		ds.Status.DesiredNumberScheduled,
+		ds.Status.CurrentNumberScheduled,
+		ds.Status.NumberReady,
+		ds.Status.UpdatedNumberScheduled,
+		ds.Status.NumberAvailable,
+		utils.FormatNodeSelector(podSpec.NodeSelector),
+		utils.CPUMillicores(resources.CPURequests),
+		utils.MemoryMiB(resources.MemoryRequests),
+		utils.CPUMillicores(resources.CPULimits),
+		utils.MemoryMiB(resources.MemoryLimits),
+		utils.CPUMillicores(resources.CPUDiff()),
+		utils.MemoryMiB(resources.MemoryDiff()),
+		resources.MemoryReadiness(),
+		imageVersions,
+		resources.QoSClass,
+		utils.ResolveOwner(d.clientset, ds.ObjectMeta),
+		resources.DefaultsApplied,
+		strings.Join(resources.LimitRangeViolations, "; "),
+		utils.CPUMillicores(resources.InitCPURequests),
+		utils.MemoryMiB(resources.InitMemoryRequests),
+		utils.CPUMillicores(resources.SidecarCPURequests),
+		utils.MemoryMiB(resources.SidecarMemoryRequests),
+		utils.CPUMillicores(resources.OverheadCPU),
+		utils.MemoryMiB(resources.OverheadMemory),
+		utils.CPUMillicores(workload.TotalCPURequests()),
+		utils.MemoryMiB(workload.TotalMemoryRequests()),
+		utils.CPUMillicores(workload.TotalCPULimits()),
+		utils.MemoryMiB(workload.TotalMemoryLimits()),
+		imageFindingsCell(workload),
+		securityFindingsCell(workload),
+	}
+}
 
-		for i, value := range record {
-			cell, err := excelize.CoordinatesToCellName(i+1, rowIndex)
-			if err != nil {
-				utils.Error("Failed to convert coordinates to cell name for DaemonSet", zap.String("daemonSetName", ds.Name), zap.Error(err))
-				return err
-			}
-			if err := f.SetCellValue(sheetName, cell, value); err != nil {
-				utils.Error("Failed to set cell value for DaemonSet", zap.String("daemonSetName", ds.Name), zap.Error(err))
-				return err
-			}
+// WriteReport writes the information of the fetched DaemonSets to a report section.
+func (d *DaemonSetHandler) WriteReport(writer utils.ReportWriter, section string) error {
+	utils.Info("Writing DaemonSets data to report", zap.String("section", section))
+	if err := writer.AddSection(section, DaemonSetHeaders); err != nil {
+		utils.Error("Failed to add DaemonSets section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+	for _, ds := range d.DaemonSets {
+		workload := d.workload(ds)
+		RecordWorkload(workload)
+		if err := writer.WriteRow(section, d.record(ds, workload)); err != nil {
+			utils.Error("Failed to write report row for DaemonSet", zap.String("daemonSetName", ds.Name), zap.Error(err))
+			return err
 		}
-		rowIndex++
 	}
-	utils.Info("Successfully written DaemonSet data to Excel sheet", zap.String("sheetName", sheetName))
+	utils.Info("Successfully written DaemonSet data to report", zap.String("section", section))
 	return nil
 }
@@ -5,9 +5,8 @@ package handlers
 import (
 	"context"
 	"k8s-reporter/utils"
-	"strconv"
+	"strings"
 
-	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,6 +17,7 @@ import (
 // for Kubernetes Deployments.
 type DeploymentHandler struct {
 	Deployments []appsv1.Deployment
+	clientset   kubernetes.Interface
 }
 
 var DeploymentHeaders = []string{
@@ -29,20 +29,34 @@ var DeploymentHeaders = []string{
 	"Up-to-date",
 	"Available",
 	"Node Selector",
-	"CPU Requests",
-	"Memory Requests",
-	"CPU Limits",
-	"Memory Limits",
-	"CPU Diff",
-	"Memory Diff",
+	"CPU Requests (m)",
+	"Memory Requests (MiB)",
+	"CPU Limits (m)",
+	"Memory Limits (MiB)",
+	"CPU Diff (m)",
+	"Memory Diff (MiB)",
 	"Memory diff > 2 x Request",
 	"Image Versions",
 	"QoS Class",
 	"Owner",
+	"LimitRange Defaults Applied",
+	"LimitRange Violations",
+	"Init CPU Requests (m)",
+	"Init Memory Requests (MiB)",
+	"Sidecar CPU Requests (m)",
+	"Sidecar Memory Requests (MiB)",
+	"Overhead CPU (m)",
+	"Overhead Memory (MiB)",
+	"Total CPU Requests (m)",
+	"Total Memory Requests (MiB)",
+	"Total CPU Limits (m)",
+	"Total Memory Limits (MiB)",
+	"Image Findings",
+	"Security Findings",
 }
 
 // FetchResources fetches all Deployments across all namespaces and stores them.
-func (d *DeploymentHandler) FetchResources(clientset *kubernetes.Clientset) error {
+func (d *DeploymentHandler) FetchResources(clientset kubernetes.Interface) error {
 	utils.Info("Fetching Deployments from Kubernetes cluster")
 	deployments, err := clientset.AppsV1().Deployments("").List(context.Background(), metav1.ListOptions{})
 	if err != nil {
@@ -50,65 +64,100 @@ func (d *DeploymentHandler) FetchResources(clientset *kubernetes.Clientset) erro
 		return err
 	}
 	d.Deployments = deployments.Items
+	d.clientset = clientset
 	utils.Info("Fetched Deployments", zap.Int("count", len(d.Deployments)))
 	return nil
 }
 
-// WriteExcel writes the information of the fetched Deployments to an Excel sheet.
-func (d *DeploymentHandler) WriteExcel(clientset *kubernetes.Clientset, f *excelize.File, sheetName string) error {
-	utils.Info("Writing Deployments data to Excel sheet", zap.String("sheetName", sheetName))
-	// Starting from the second row, since the first row is for headers
-	rowIndex := 2
-	for _, deployment := range d.Deployments {
-		name := deployment.Name
-		namespace := deployment.Namespace
-		desiredReplicas := deployment.Spec.Replicas
-		currentReplicas := deployment.Status.Replicas
-		availableReplicas := deployment.Status.AvailableReplicas
-		readyReplicas := deployment.Status.ReadyReplicas
-		uptodateReplicas := deployment.Status.UpdatedReplicas
-		nodeSelector := deployment.Spec.Template.Spec.NodeSelector
-		cpuRequests, memoryRequests, cpuLimits, memoryLimits, cpuDiff, memoryDiff, memoryReadiness, qosClass := utils.ExtractResources(clientset, deployment.Spec.Template.Spec, namespace)
-		imageVersions := utils.ExtractImageVersions(deployment.Spec.Template.Spec)
-		// qosClass := utils.DetermineQoSClass(deployment.Spec.Template.Spec)
-		desired := "unknown"
-		if desiredReplicas != nil {
-			desired = strconv.Itoa(int(*desiredReplicas))
-		}
+// workload builds the common view of a single Deployment, running as many pods as its desired replicas.
+func (d *DeploymentHandler) workload(deployment appsv1.Deployment) Workload {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return Workload{
+		Kind:            "Deployment",
+		Name:            deployment.Name,
+		Namespace:       deployment.Namespace,
+		Object:          &deployment,
+		Replicas:        replicas,
+		Resources:       utils.ExtractResources(d.clientset, deployment.Spec.Template.Spec, deployment.Namespace),
+		PodSpec:         deployment.Spec.Template.Spec,
+		PodAnnotations:  deployment.Spec.Template.Annotations,
+		NamespaceLabels: utils.GetNamespaceLabels(d.clientset, deployment.Namespace),
+		Usage:           utils.GetWorkloadUsage(d.clientset, "Deployment", deployment.Namespace, deployment.Name),
+	}
+}
 
-		record := []interface{}{
-			name,
-			namespace,
-			desired,
-			strconv.Itoa(int(currentReplicas)),
-			strconv.Itoa(int(readyReplicas)),
-			strconv.Itoa(int(uptodateReplicas)),
-			strconv.Itoa(int(availableReplicas)),
-			utils.FormatNodeSelector(nodeSelector),
-			cpuRequests,
-			memoryRequests,
-			cpuLimits,
-			memoryLimits,
-			cpuDiff,
-			memoryDiff,
-			memoryReadiness,
-			imageVersions,
-			qosClass,
-		}
+// record builds the report row of a single Deployment.
+func (d *DeploymentHandler) record(deployment appsv1.Deployment, workload Workload) []interface{} {
+	name := deployment.Name
+	namespace := deployment.Namespace
+	desiredReplicas := deployment.Spec.Replicas
+	currentReplicas := deployment.Status.Replicas
+	availableReplicas := deployment.Status.AvailableReplicas
+	readyReplicas := deployment.Status.ReadyReplicas
+	uptodateReplicas := deployment.Status.UpdatedReplicas
+	nodeSelector := deployment.Spec.Template.Spec.NodeSelector
+	resources := workload.Resources
+	imageVersions := utils.ExtractImageVersions(deployment.Spec.Template.Spec)
+	// Left empty when unknown
+	var desired interface{}
+	if desiredReplicas != nil {
+		desired = *desiredReplicas
+	}
 
-		for i, value := range record {
-			cell, err := excelize.CoordinatesToCellName(i+1, rowIndex)
-			if err != nil {
-				utils.Error("Failed to convert coordinates to cell name for Deployment", zap.String("deploymentName", name), zap.Error(err))
-				return err
-			}
-			if err := f.SetCellValue(sheetName, cell, value); err != nil {
-				utils.Error("Failed to set cell value for Deployment", zap.String("deploymentName", name), zap.Error(err))
-				return err
-			}
+	return []interface{}{
+		name,
+		namespace,
+		desired,
+		currentReplicas,
+		readyReplicas,
+		uptodateReplicas,
+		availableReplicas,
+		utils.FormatNodeSelector(nodeSelector),
+		utils.CPUMillicores(resources.CPURequests),
+		utils.MemoryMiB(resources.MemoryRequests),
+		utils.CPUMillicores(resources.CPULimits),
+		utils.MemoryMiB(resources.MemoryLimits),
+		utils.CPUMillicores(resources.CPUDiff()),
+		utils.MemoryMiB(resources.MemoryDiff()),
+		resources.MemoryReadiness(),
+		imageVersions,
+		resources.QoSClass,
+		utils.ResolveOwner(d.clientset, deployment.ObjectMeta),
+		resources.DefaultsApplied,
+		strings.Join(resources.LimitRangeViolations, "; "),
+		utils.CPUMillicores(resources.InitCPURequests),
+		utils.MemoryMiB(resources.InitMemoryRequests),
+		utils.CPUMillicores(resources.SidecarCPURequests),
+		utils.MemoryMiB(resources.SidecarMemoryRequests),
+		utils.CPUMillicores(resources.OverheadCPU),
+		utils.MemoryMiB(resources.OverheadMemory),
+		utils.CPUMillicores(workload.TotalCPURequests()),
+		utils.MemoryMiB(workload.TotalMemoryRequests()),
+		utils.CPUMillicores(workload.TotalCPULimits()),
+		utils.MemoryMiB(workload.TotalMemoryLimits()),
+		imageFindingsCell(workload),
+		securityFindingsCell(workload),
+	}
+}
+
+// WriteReport writes the information of the fetched Deployments to a report section.
+func (d *DeploymentHandler) WriteReport(writer utils.ReportWriter, section string) error {
+	utils.Info("Writing Deployments data to report", zap.String("section", section))
+	if err := writer.AddSection(section, DeploymentHeaders); err != nil {
+		utils.Error("Failed to add Deployments section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+	for _, deployment := range d.Deployments {
+		workload := d.workload(deployment)
+		RecordWorkload(workload)
+		if err := writer.WriteRow(section, d.record(deployment, workload)); err != nil {
+			utils.Error("Failed to write report row for Deployment", zap.String("deploymentName", deployment.Name), zap.Error(err))
+			return err
 		}
-		rowIndex++
 	}
-	utils.Info("Successfully written Deployment data to Excel sheet", zap.String("sheetName", sheetName))
+	utils.Info("Successfully written Deployment data to report", zap.String("section", section))
 	return nil
 }
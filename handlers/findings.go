@@ -0,0 +1,162 @@
+// handlers/findings.go
+
+package handlers
+
+import (
+	"k8s-reporter/utils"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// Finding is a problem found on a workload by one of the checks of k8s-reporter.
+type Finding struct {
+	RuleID   string
+	Severity string
+	// Category is the check that found the problem, e.g. "image", "security", "pod-security" or "policy".
+	Category  string
+	Message   string
+	Kind      string
+	Namespace string
+	Name      string
+	// Container is the container the finding is about, empty when it is about the whole workload.
+	Container string
+	// Source is the manifest file the workload was read from, empty when it is unknown.
+	Source string
+}
+
+var FindingsHeaders = []string{
+	"Rule ID",
+	"Severity",
+	"Category",
+	"Kind",
+	"Namespace",
+	"Name",
+	"Container",
+	"Message",
+	"Source",
+}
+
+// newFinding returns a finding about a workload, or one of its containers.
+func newFinding(workload Workload, container string, category string, ruleID string, severity string, message string) Finding {
+	return Finding{
+		RuleID:    ruleID,
+		Severity:  severity,
+		Category:  category,
+		Message:   message,
+		Kind:      workload.Kind,
+		Namespace: workload.Namespace,
+		Name:      workload.Name,
+		Container: container,
+		Source:    workloadSource(workload),
+	}
+}
+
+// workloadSource returns the manifest file of a workload, from its k8s-reporter.io/source or
+// config.kubernetes.io/path annotation.
+func workloadSource(workload Workload) string {
+	if workload.Object == nil {
+		return ""
+	}
+	object, err := meta.Accessor(workload.Object)
+	if err != nil {
+		return ""
+	}
+	annotations := object.GetAnnotations()
+	if source := annotations[utils.SourceAnnotation]; source != "" {
+		return source
+	}
+	return annotations[utils.SourcePathAnnotation]
+}
+
+// CheckWorkload runs every enabled check on a workload and returns its findings.
+func CheckWorkload(workload Workload) ([]Finding, error) {
+	findings := checkImages(workload)
+	findings = append(findings, auditSecurity(workload).findings...)
+	podSecurityFindings, err := checkPodSecurity(workload)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, podSecurityFindings...)
+	return append(findings, checkPolicies(workload)...), nil
+}
+
+// CheckWorkloads runs every enabled check on the given workloads and returns their findings.
+func CheckWorkloads(workloads []Workload) ([]Finding, error) {
+	var findings []Finding
+	for _, workload := range workloads {
+		workloadFindings, err := CheckWorkload(workload)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, workloadFindings...)
+	}
+	return findings, nil
+}
+
+// WriteFindings writes one row per finding.
+func WriteFindings(writer utils.ReportWriter, section string, findings []Finding) error {
+	utils.Info("Writing Findings to report", zap.String("section", section), zap.Int("count", len(findings)))
+	if err := writer.AddSection(section, FindingsHeaders); err != nil {
+		utils.Error("Failed to add Findings section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+	for _, finding := range findings {
+		record := []interface{}{
+			finding.RuleID,
+			finding.Severity,
+			finding.Category,
+			finding.Kind,
+			finding.Namespace,
+			finding.Name,
+			finding.Container,
+			finding.Message,
+			finding.Source,
+		}
+		if err := writer.WriteRow(section, record); err != nil {
+			utils.Error("Failed to write report row for finding", zap.String("ruleID", finding.RuleID), zap.String("name", finding.Name), zap.Error(err))
+			return err
+		}
+	}
+	utils.Info("Successfully written Findings to report", zap.String("section", section))
+	return nil
+}
+
+// FindingsSummary counts the findings of a run, the machine-readable summary of --summary-file.
+type FindingsSummary struct {
+	Workloads int `json:"workloads"`
+	Findings  int `json:"findings"`
+	// Severities, Categories and Rules count the findings per severity, category and rule ID.
+	Severities      map[string]int `json:"severities"`
+	Categories      map[string]int `json:"categories"`
+	Rules           map[string]int `json:"rules"`
+	HighestSeverity string         `json:"highestSeverity,omitempty"`
+	// FailOn is the --fail-on severity, Failed tells whether some findings reached it.
+	FailOn string `json:"failOn,omitempty"`
+	Failed bool   `json:"failed"`
+}
+
+// SummarizeFindings counts the findings of the given workloads and checks them against a --fail-on
+// severity: the summary fails when a finding is at least as severe, and never with an empty failOn.
+func SummarizeFindings(workloads []Workload, findings []Finding, failOn string) FindingsSummary {
+	summary := FindingsSummary{
+		Workloads:  len(workloads),
+		Findings:   len(findings),
+		Severities: map[string]int{utils.SeverityError: 0, utils.SeverityWarning: 0, utils.SeverityInfo: 0},
+		Categories: map[string]int{},
+		Rules:      map[string]int{},
+		FailOn:     failOn,
+	}
+	for _, finding := range findings {
+		summary.Severities[finding.Severity]++
+		summary.Categories[finding.Category]++
+		summary.Rules[finding.RuleID]++
+		if failOn != "" && utils.SeverityAtLeast(finding.Severity, failOn) {
+			summary.Failed = true
+		}
+	}
+	if severity, ok := highestSeverity(findings).(string); ok {
+		summary.HighestSeverity = severity
+	}
+	return summary
+}
@@ -3,14 +3,26 @@
 package handlers
 
 import (
-	"encoding/csv"
+	"k8s-reporter/utils"
 
 	"k8s.io/client-go/kubernetes"
 )
 
 // ResourceHandler defines the methods required to fetch Kubernetes resources
-// and write their information to a CSV file.
+// and feed their information into a report, whatever its output format.
 type ResourceHandler interface {
-	FetchResources(clientset *kubernetes.Clientset) error
-	WriteCSV(writer *csv.Writer) error
+	FetchResources(clientset kubernetes.Interface) error
+	WriteReport(writer utils.ReportWriter, section string) error
 }
+
+// Ensure every handler satisfies the ResourceHandler interface.
+var (
+	_ ResourceHandler = &DeploymentHandler{}
+	_ ResourceHandler = &DaemonSetHandler{}
+	_ ResourceHandler = &StatefulsetHandler{}
+	_ ResourceHandler = &JobHandler{}
+	_ ResourceHandler = &CronJobHandler{}
+	_ ResourceHandler = &PodHandler{}
+	_ ResourceHandler = &NodeHandler{}
+	_ ResourceHandler = &RecommendationHandler{}
+)
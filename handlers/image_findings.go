@@ -0,0 +1,79 @@
+// handlers/image_findings.go
+
+package handlers
+
+import (
+	"fmt"
+	"k8s-reporter/utils"
+	"strings"
+)
+
+// imageCategory is the category of the findings about images.
+const imageCategory = "image"
+
+// bannedImageRule is the rule ID of the findings about banned images.
+const bannedImageRule = "banned-image"
+
+// vulnerabilitySeverity maps the severity of a vulnerability database to the severity of a finding.
+func vulnerabilitySeverity(severity string) string {
+	switch strings.ToUpper(severity) {
+	case "CRITICAL", "HIGH":
+		return utils.SeverityError
+	case "MEDIUM":
+		return utils.SeverityWarning
+	default:
+		return utils.SeverityInfo
+	}
+}
+
+// checkImages matches the image of every container of a workload against the --vuln-db database,
+// and returns a finding per known vulnerability and per banned image. It finds nothing without a database.
+func checkImages(workload Workload) []Finding {
+	db := utils.GetVulnerabilityDB()
+	if db == nil {
+		return nil
+	}
+
+	var findings []Finding
+	for _, image := range utils.ExtractContainerImages(workload.PodSpec) {
+		if image.Err != nil {
+			continue
+		}
+		for _, banned := range db.BannedBy(image.Reference) {
+			message := fmt.Sprintf("Image %s is banned", image.Image)
+			if banned.Reason != "" {
+				message += ": " + banned.Reason
+			}
+			findings = append(findings, newFinding(workload, image.Container, imageCategory, bannedImageRule, utils.SeverityError, message))
+		}
+		for _, vulnerability := range db.VulnerabilitiesOf(image.Reference) {
+			message := fmt.Sprintf("Image %s is affected by %s (%s)", image.Image, vulnerability.ID, vulnerability.Severity)
+			if vulnerability.Package != "" {
+				message += fmt.Sprintf(" in %s %s", vulnerability.Package, vulnerability.InstalledVersion)
+			}
+			if vulnerability.Title != "" {
+				message += ": " + vulnerability.Title
+			}
+			if vulnerability.FixedVersion != "" {
+				message += fmt.Sprintf(", fixed in %s", vulnerability.FixedVersion)
+			}
+			findings = append(findings, newFinding(workload, image.Container, imageCategory, vulnerability.ID, vulnerabilitySeverity(vulnerability.Severity), message))
+		}
+	}
+	return findings
+}
+
+// imageFindingsCell summarizes the image findings of a workload for the "Image Findings" column,
+// e.g. "app: CVE-2023-44487, sidecar: banned-image".
+func imageFindingsCell(workload Workload) string {
+	var cells []string
+	seen := map[string]bool{}
+	for _, finding := range checkImages(workload) {
+		cell := fmt.Sprintf("%s: %s", finding.Container, finding.RuleID)
+		if !seen[cell] {
+			seen[cell] = true
+			cells = append(cells, cell)
+		}
+	}
+	return strings.Join(cells, ", ")
+}
@@ -0,0 +1,60 @@
+// handlers/image_inventory.go
+
+package handlers
+
+import (
+	"fmt"
+	"k8s-reporter/utils"
+
+	"go.uber.org/zap"
+)
+
+// Names of the CycloneDX properties recording where an image runs.
+const (
+	workloadProperty  = "k8s-reporter:workload"
+	namespaceProperty = "k8s-reporter:namespace"
+)
+
+// inventoryImage is an image of the inventory and the workloads running it.
+type inventoryImage struct {
+	reference  utils.ImageReference
+	workloads  map[string]bool
+	namespaces map[string]bool
+}
+
+// BuildImageBOM de-duplicates the images of every container (app, init and sidecar) of the given
+// workloads and returns them as a CycloneDX bill of materials, each image listing the workloads
+// and namespaces using it. Images are identified by their normalized reference, so "nginx:1.25" and
+// "docker.io/library/nginx:1.25" are the same component.
+func BuildImageBOM(workloads []Workload) *utils.CycloneDXBOM {
+	images := map[string]*inventoryImage{}
+	for _, workload := range workloads {
+		for _, image := range utils.ExtractContainerImages(workload.PodSpec) {
+			if image.Err != nil {
+				utils.Warn("Image left out of the BOM", zap.String("name", workload.Name), zap.String("containerName", image.Container), zap.Error(image.Err))
+				continue
+			}
+			key := image.Reference.String()
+			if images[key] == nil {
+				images[key] = &inventoryImage{reference: image.Reference, workloads: map[string]bool{}, namespaces: map[string]bool{}}
+			}
+			images[key].workloads[fmt.Sprintf("%s/%s/%s", workload.Kind, workload.Namespace, workload.Name)] = true
+			images[key].namespaces[workload.Namespace] = true
+		}
+	}
+
+	bom := utils.NewCycloneDXBOM()
+	for _, key := range sortedKeys(images) {
+		image := images[key]
+		component := utils.NewContainerComponent(image.reference)
+		for _, workload := range sortedKeys(image.workloads) {
+			component.Properties = append(component.Properties, utils.CycloneDXProperty{Name: workloadProperty, Value: workload})
+		}
+		for _, namespace := range sortedKeys(image.namespaces) {
+			component.Properties = append(component.Properties, utils.CycloneDXProperty{Name: namespaceProperty, Value: namespace})
+		}
+		bom.Components = append(bom.Components, component)
+	}
+	utils.Info("Built image inventory", zap.Int("images", len(bom.Components)))
+	return bom
+}
@@ -0,0 +1,73 @@
+// handlers/images.go
+
+package handlers
+
+import (
+	"k8s-reporter/utils"
+
+	"go.uber.org/zap"
+)
+
+var ImagesHeaders = []string{
+	"Kind",
+	"Namespace",
+	"Name",
+	"Container",
+	"Container Type",
+	"Image",
+	"Registry",
+	"Repository",
+	"Tag",
+	"Digest",
+	"Image Pull Policy",
+	"Latest",
+	"Untagged",
+}
+
+// imageRecord builds the report row of the image of a single container. The parts of the reference
+// are left empty when it cannot be parsed.
+func imageRecord(workload Workload, image utils.ContainerImage) []interface{} {
+	record := []interface{}{
+		workload.Kind,
+		workload.Namespace,
+		workload.Name,
+		image.Container,
+		image.Type,
+		image.Image,
+	}
+	if image.Err != nil {
+		return append(record, nil, nil, nil, nil, string(image.PullPolicy), nil, nil)
+	}
+	return append(record,
+		image.Reference.Registry,
+		image.Reference.Repository,
+		image.Reference.Tag,
+		image.Reference.Digest,
+		string(image.PullPolicy),
+		image.Reference.Latest(),
+		image.Reference.Untagged(),
+	)
+}
+
+// WriteImages writes one row per container of the given workloads with its image reference split
+// into registry, repository, tag and digest, flagging the images that float with "latest" or have no tag.
+func WriteImages(writer utils.ReportWriter, section string, workloads []Workload) error {
+	utils.Info("Writing Images to report", zap.String("section", section))
+	if err := writer.AddSection(section, ImagesHeaders); err != nil {
+		utils.Error("Failed to add Images section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+	for _, workload := range workloads {
+		for _, image := range utils.ExtractContainerImages(workload.PodSpec) {
+			if image.Err != nil {
+				utils.Warn("Failed to parse image reference", zap.String("name", workload.Name), zap.String("containerName", image.Container), zap.Error(image.Err))
+			}
+			if err := writer.WriteRow(section, imageRecord(workload, image)); err != nil {
+				utils.Error("Failed to write report row for image", zap.String("name", workload.Name), zap.String("containerName", image.Container), zap.Error(err))
+				return err
+			}
+		}
+	}
+	utils.Info("Successfully written Images to report", zap.String("section", section))
+	return nil
+}
@@ -5,8 +5,8 @@ package handlers
 import (
 	"context"
 	"k8s-reporter/utils"
+	"strings"
 
-	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
 	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,27 +16,48 @@ import (
 // JobHandler is a struct that implements the ResourceHandler interface
 // for Kubernetes Jobs.
 type JobHandler struct {
-	Jobs []batchv1.Job
+	Jobs      []batchv1.Job
+	clientset kubernetes.Interface
 }
 
 var JobHeaders = []string{
 	"Name",
 	"Namespace",
 	"Node Selector",
-	"CPU Requests",
-	"Memory Requests",
-	"CPU Limits",
-	"Memory Limits",
-	"CPU Diff",
-	"Memory Diff",
+	"CPU Requests (m)",
+	"Memory Requests (MiB)",
+	"CPU Limits (m)",
+	"Memory Limits (MiB)",
+	"CPU Diff (m)",
+	"Memory Diff (MiB)",
 	"Memory diff > 2 x Request",
 	"Image Versions",
 	"QoS Class",
 	"Owner",
+	"LimitRange Defaults Applied",
+	"LimitRange Violations",
+	"Init CPU Requests (m)",
+	"Init Memory Requests (MiB)",
+	"Sidecar CPU Requests (m)",
+	"Sidecar Memory Requests (MiB)",
+	"Overhead CPU (m)",
+	"Overhead Memory (MiB)",
+	"Total CPU Requests (m)",
+	"Total Memory Requests (MiB)",
+	"Total CPU Limits (m)",
+	"Total Memory Limits (MiB)",
+	"Image Findings",
+	"Security Findings",
+}
+
+// spawnedByCronJob reports whether a Job was created by a CronJob, which stands for it as a workload.
+func spawnedByCronJob(job *batchv1.Job) bool {
+	owner := metav1.GetControllerOf(job)
+	return owner != nil && owner.Kind == "CronJob"
 }
 
 // FetchResources fetches all Jobs across all namespaces and stores them.
-func (j *JobHandler) FetchResources(clientset *kubernetes.Clientset) error {
+func (j *JobHandler) FetchResources(clientset kubernetes.Interface) error {
 	utils.Info("Fetching Jobs from Kubernetes cluster")
 	jobs, err := clientset.BatchV1().Jobs("").List(context.Background(), metav1.ListOptions{})
 	if err != nil {
@@ -44,51 +65,88 @@ func (j *JobHandler) FetchResources(clientset *kubernetes.Clientset) error {
 		return err
 	}
 	j.Jobs = jobs.Items
+	j.clientset = clientset
 	utils.Info("Fetched Jobs", zap.Int("count", len(j.Jobs)))
 	return nil
 }
 
-// WriteExcel writes the information of the fetched Jobs to an Excel sheet.
-func (j *JobHandler) WriteExcel(clientset *kubernetes.Clientset, f *excelize.File, sheetName string) error {
-	utils.Info("Writing Jobs data to Excel sheet", zap.String("sheetName", sheetName))
-	// Starting from the second row, since the first row is for headers
-	rowIndex := 2
-	for _, job := range j.Jobs {
-		name := job.Name
-		namespace := job.Namespace
-		nodeSelector := job.Spec.Template.Spec.NodeSelector
-		cpuRequests, memoryRequests, cpuLimits, memoryLimits, cpuDiff, memoryDiff, memoryReadiness, qosClass := utils.ExtractResources(clientset, job.Spec.Template.Spec, namespace)
-		imageVersions := utils.ExtractImageVersions(job.Spec.Template.Spec)
-		// qosClass := utils.DetermineQoSClass(job.Spec.Template.Spec)
+// workload builds the common view of a single Job, running as many pods as its parallelism.
+func (j *JobHandler) workload(job batchv1.Job) Workload {
+	replicas := int32(1)
+	if job.Spec.Parallelism != nil {
+		replicas = *job.Spec.Parallelism
+	}
+	return Workload{
+		Kind:            "Job",
+		Name:            job.Name,
+		Namespace:       job.Namespace,
+		Object:          &job,
+		Replicas:        replicas,
+		Resources:       utils.ExtractResources(j.clientset, job.Spec.Template.Spec, job.Namespace),
+		PodSpec:         job.Spec.Template.Spec,
+		PodAnnotations:  job.Spec.Template.Annotations,
+		NamespaceLabels: utils.GetNamespaceLabels(j.clientset, job.Namespace),
+		Usage:           utils.GetWorkloadUsage(j.clientset, "Job", job.Namespace, job.Name),
+	}
+}
 
-		record := []interface{}{
-			name,
-			namespace,
-			utils.FormatNodeSelector(nodeSelector),
-			cpuRequests,
-			memoryRequests,
-			cpuLimits,
-			memoryLimits,
-			cpuDiff,
-			memoryDiff,
-			memoryReadiness,
-			imageVersions,
-			qosClass,
-		}
+// record builds the report row of a single Job.
+func (j *JobHandler) record(job batchv1.Job, workload Workload) []interface{} {
+	name := job.Name
+	namespace := job.Namespace
+	nodeSelector := job.Spec.Template.Spec.NodeSelector
+	resources := workload.Resources
+	imageVersions := utils.ExtractImageVersions(job.Spec.Template.Spec)
 
-		for i, value := range record {
-			cell, err := excelize.CoordinatesToCellName(i+1, rowIndex)
-			if err != nil {
-				utils.Error("Failed to convert coordinates to cell name for Job", zap.String("jobName", name), zap.Error(err))
-				return err
-			}
-			if err := f.SetCellValue(sheetName, cell, value); err != nil {
-				utils.Error("Failed to set cell value for Job", zap.String("jobName", name), zap.Error(err))
-				return err
-			}
+	return []interface{}{
+		name,
+		namespace,
+		utils.FormatNodeSelector(nodeSelector),
+		utils.CPUMillicores(resources.CPURequests),
+		utils.MemoryMiB(resources.MemoryRequests),
+		utils.CPUMillicores(resources.CPULimits),
+		utils.MemoryMiB(resources.MemoryLimits),
+		utils.CPUMillicores(resources.CPUDiff()),
+		utils.MemoryMiB(resources.MemoryDiff()),
+		resources.MemoryReadiness(),
+		imageVersions,
+		resources.QoSClass,
+		utils.ResolveOwner(j.clientset, job.ObjectMeta),
+		resources.DefaultsApplied,
+		strings.Join(resources.LimitRangeViolations, "; "),
+		utils.CPUMillicores(resources.InitCPURequests),
+		utils.MemoryMiB(resources.InitMemoryRequests),
+		utils.CPUMillicores(resources.SidecarCPURequests),
+		utils.MemoryMiB(resources.SidecarMemoryRequests),
+		utils.CPUMillicores(resources.OverheadCPU),
+		utils.MemoryMiB(resources.OverheadMemory),
+		utils.CPUMillicores(workload.TotalCPURequests()),
+		utils.MemoryMiB(workload.TotalMemoryRequests()),
+		utils.CPUMillicores(workload.TotalCPULimits()),
+		utils.MemoryMiB(workload.TotalMemoryLimits()),
+		imageFindingsCell(workload),
+		securityFindingsCell(workload),
+	}
+}
+
+// WriteReport writes the information of the fetched Jobs to a report section.
+func (j *JobHandler) WriteReport(writer utils.ReportWriter, section string) error {
+	utils.Info("Writing Jobs data to report", zap.String("section", section))
+	if err := writer.AddSection(section, JobHeaders); err != nil {
+		utils.Error("Failed to add Jobs section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+	for _, job := range j.Jobs {
+		workload := j.workload(job)
+		// The Jobs of a CronJob keep their row, but only their CronJob is a workload of the run.
+		if !spawnedByCronJob(&job) {
+			RecordWorkload(workload)
+		}
+		if err := writer.WriteRow(section, j.record(job, workload)); err != nil {
+			utils.Error("Failed to write report row for Job", zap.String("jobName", job.Name), zap.Error(err))
+			return err
 		}
-		rowIndex++
 	}
-	utils.Info("Successfully written Job data to Excel sheet", zap.String("sheetName", sheetName))
+	utils.Info("Successfully written Job data to report", zap.String("section", section))
 	return nil
 }
@@ -0,0 +1,118 @@
+// handlers/job_handler_test.go
+
+package handlers
+
+import (
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// recordingWriter is a ReportWriter keeping the rows written to each section.
+type recordingWriter struct {
+	rows map[string][][]interface{}
+}
+
+func (w *recordingWriter) AddSection(section string, headers []string) error {
+	if w.rows == nil {
+		w.rows = map[string][][]interface{}{}
+	}
+	return nil
+}
+
+func (w *recordingWriter) WriteRow(section string, record []interface{}) error {
+	w.rows[section] = append(w.rows[section], record)
+	return nil
+}
+
+func (w *recordingWriter) Close() error { return nil }
+
+// resetWorkloads clears the workloads recorded by a previous test.
+func resetWorkloads(t *testing.T) {
+	t.Helper()
+	workloadsMutex.Lock()
+	workloads = nil
+	workloadsMutex.Unlock()
+	t.Cleanup(func() {
+		workloadsMutex.Lock()
+		workloads = nil
+		workloadsMutex.Unlock()
+	})
+}
+
+var testPodTemplate = v1.PodTemplateSpec{
+	Spec: v1.PodSpec{Containers: []v1.Container{{Name: "main", Image: "busybox:1.36"}}},
+}
+
+func TestJobHandlerSkipsCronJobRuns(t *testing.T) {
+	resetWorkloads(t)
+	isController := true
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default", UID: "cronjob-uid"},
+		Spec: batchv1.CronJobSpec{
+			Schedule:    "0 * * * *",
+			JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: testPodTemplate}},
+		},
+		Status: batchv1.CronJobStatus{Active: []v1.ObjectReference{{Kind: "Job", Namespace: "default", Name: "backup-28000000"}}},
+	}
+	spawned := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup-28000000",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "batch/v1", Kind: "CronJob", Name: "backup", UID: "cronjob-uid", Controller: &isController,
+			}},
+		},
+		Spec: batchv1.JobSpec{Template: testPodTemplate},
+	}
+	standalone := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default"},
+		Spec:       batchv1.JobSpec{Template: testPodTemplate},
+	}
+	clientset := fake.NewSimpleClientset(cronJob, spawned, standalone)
+
+	writer := &recordingWriter{}
+	for _, handler := range []struct {
+		handler ResourceHandler
+		section string
+	}{{&JobHandler{}, "Jobs"}, {&CronJobHandler{}, "CronJobs"}} {
+		if err := handler.handler.FetchResources(clientset); err != nil {
+			t.Fatalf("FetchResources: %v", err)
+		}
+		if err := handler.handler.WriteReport(writer, handler.section); err != nil {
+			t.Fatalf("WriteReport: %v", err)
+		}
+	}
+
+	if rows := len(writer.rows["Jobs"]); rows != 2 {
+		t.Errorf("Jobs section has %d rows, want 2", rows)
+	}
+	got := map[string]int{}
+	for _, workload := range Workloads() {
+		got[workload.Kind+"/"+workload.Name]++
+		// the pod of the active run is counted once, on the CronJob
+		if workload.Kind == "CronJob" && workload.Replicas != 1 {
+			t.Errorf("CronJob has %d replicas, want 1", workload.Replicas)
+		}
+	}
+	want := map[string]int{"Job/migrate": 1, "CronJob/backup": 1}
+	if len(got) != len(want) {
+		t.Fatalf("recorded workloads %v, want %v", got, want)
+	}
+	for key, count := range want {
+		if got[key] != count {
+			t.Errorf("recorded workloads %v, want %v", got, want)
+		}
+	}
+
+	fetched, err := FetchWorkloads(clientset)
+	if err != nil {
+		t.Fatalf("FetchWorkloads: %v", err)
+	}
+	if len(fetched) != 2 {
+		t.Errorf("FetchWorkloads returned %d workloads, want 2", len(fetched))
+	}
+}
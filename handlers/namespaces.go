@@ -0,0 +1,110 @@
+// handlers/namespaces.go
+
+package handlers
+
+import (
+	"k8s-reporter/utils"
+
+	"go.uber.org/zap"
+)
+
+// namespaceKinds are the workload kinds counted in their own column of the Namespaces section.
+var namespaceKinds = []string{"Deployment", "DaemonSet", "StatefulSet", "Job", "CronJob"}
+
+// qosClasses are the QoS classes counted in their own column of the Namespaces section.
+var qosClasses = []string{"Guaranteed", "Burstable", "BestEffort"}
+
+var NamespacesHeaders = []string{
+	"Namespace",
+	"Workloads",
+	"Deployments",
+	"DaemonSets",
+	"StatefulSets",
+	"Jobs",
+	"CronJobs",
+	"Total CPU Requests (m)",
+	"Total Memory Requests (MiB)",
+	"Total CPU Limits (m)",
+	"Total Memory Limits (MiB)",
+	"Guaranteed",
+	"Burstable",
+	"BestEffort",
+	"LimitRange Defaults Applied",
+	"Monthly Cost (Requests)",
+	"Monthly Cost (Usage)",
+}
+
+// namespaceSummary aggregates the workloads of a single namespace.
+type namespaceSummary struct {
+	footprint
+	kinds           map[string]int
+	qosClasses      map[string]int
+	defaultsApplied int
+	// cost is the monthly cost of the workloads, nil unless --pricing is set.
+	cost *workloadCost
+}
+
+func (s *namespaceSummary) add(workload Workload, prices *utils.PriceTable) {
+	s.footprint.add(workload)
+	if prices != nil {
+		if s.cost == nil {
+			s.cost = &workloadCost{}
+		}
+		s.cost.add(costOf(prices, workload))
+	}
+	s.kinds[workload.Kind]++
+	s.qosClasses[workload.Resources.QoSClass]++
+	if workload.Resources.DefaultsApplied {
+		s.defaultsApplied++
+	}
+}
+
+func (s *namespaceSummary) record(namespace string) []interface{} {
+	record := []interface{}{namespace, s.workloads}
+	for _, kind := range namespaceKinds {
+		record = append(record, s.kinds[kind])
+	}
+	record = append(record,
+		utils.CPUMillicores(s.cpuRequests),
+		utils.MemoryMiB(s.memoryRequests),
+		utils.CPUMillicores(s.cpuLimits),
+		utils.MemoryMiB(s.memoryLimits),
+	)
+	for _, qosClass := range qosClasses {
+		record = append(record, s.qosClasses[qosClass])
+	}
+	record = append(record, s.defaultsApplied)
+	if s.cost == nil {
+		return append(record, nil, nil)
+	}
+	return append(record, s.cost.requests(), s.cost.usageCell())
+}
+
+// WriteNamespaceSummary writes one row per namespace aggregating the given workloads: workload counts
+// by kind, total requests and limits, QoS class distribution and how many workloads fell back to
+// LimitRange defaults, and their monthly cost when --pricing is set.
+func WriteNamespaceSummary(writer utils.ReportWriter, section string, workloads []Workload) error {
+	utils.Info("Writing Namespaces summary to report", zap.String("section", section))
+	prices := utils.GetPriceTable()
+	if err := writer.AddSection(section, currencyHeaders(NamespacesHeaders, prices)); err != nil {
+		utils.Error("Failed to add Namespaces section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+
+	summaries := map[string]*namespaceSummary{}
+	for _, workload := range workloads {
+		if summaries[workload.Namespace] == nil {
+			summaries[workload.Namespace] = &namespaceSummary{kinds: map[string]int{}, qosClasses: map[string]int{}}
+		}
+		summaries[workload.Namespace].add(workload, prices)
+	}
+
+	for _, namespace := range sortedKeys(summaries) {
+		if err := writer.WriteRow(section, summaries[namespace].record(namespace)); err != nil {
+			utils.Error("Failed to write report row for namespace", zap.String("namespace", namespace), zap.Error(err))
+			return err
+		}
+	}
+	utils.Info("Successfully written Namespaces summary to report", zap.String("section", section))
+	return nil
+}
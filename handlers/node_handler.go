@@ -0,0 +1,279 @@
+// handlers/node_handler.go
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"k8s-reporter/utils"
+	"math"
+	"strings"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Well-known node labels reported in their own column.
+const (
+	zoneLabel         = "topology.kubernetes.io/zone"
+	instanceTypeLabel = "node.kubernetes.io/instance-type"
+)
+
+// NodeHandler is a struct that implements the ResourceHandler interface
+// for Kubernetes Nodes, comparing their allocatable capacity to the
+// requests and limits of the pods scheduled on them.
+type NodeHandler struct {
+	Nodes []v1.Node
+	// Pods are the non-terminated pods, used to sum what is scheduled on every node.
+	Pods      []v1.Pod
+	clientset kubernetes.Interface
+}
+
+var NodeHeaders = []string{
+	"Name",
+	"Zone",
+	"Instance Type",
+	"Kubelet Version",
+	"Conditions",
+	"Unschedulable",
+	"Taints",
+	"Pods",
+	"Allocatable Pods",
+	"CPU Capacity (m)",
+	"Memory Capacity (MiB)",
+	"CPU Allocatable (m)",
+	"Memory Allocatable (MiB)",
+	"CPU Requests (m)",
+	"Memory Requests (MiB)",
+	"CPU Limits (m)",
+	"Memory Limits (MiB)",
+	"CPU Requests (%)",
+	"Memory Requests (%)",
+	"CPU Limits (%)",
+	"Memory Limits (%)",
+	"CPU Usage (m)",
+	"Memory Usage (MiB)",
+	"CPU Usage (%)",
+	"Memory Usage (%)",
+	"Monthly Cost",
+}
+
+// nodeUsage sums the requests and limits of the pods scheduled on a node.
+type nodeUsage struct {
+	pods           int
+	cpuRequests    resource.Quantity
+	memoryRequests resource.Quantity
+	cpuLimits      resource.Quantity
+	memoryLimits   resource.Quantity
+}
+
+// FetchResources fetches all Nodes and the non-terminated Pods scheduled on them.
+func (n *NodeHandler) FetchResources(clientset kubernetes.Interface) error {
+	utils.Info("Fetching Nodes from Kubernetes cluster")
+	nodes, err := clientset.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		utils.Error("Failed to fetch Nodes", zap.Error(err))
+		return err
+	}
+	n.Nodes = nodes.Items
+	utils.Info("Fetched Nodes", zap.Int("count", len(n.Nodes)))
+
+	pods, err := clientset.CoreV1().Pods("").List(context.Background(), metav1.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		utils.Error("Failed to fetch Pods", zap.Error(err))
+		return err
+	}
+	n.Pods = pods.Items
+	n.clientset = clientset
+	utils.Info("Fetched scheduled Pods", zap.Int("count", len(n.Pods)))
+	return nil
+}
+
+// usage sums the requests and limits of the fetched pods per node name.
+func (n *NodeHandler) usage() map[string]*nodeUsage {
+	usage := map[string]*nodeUsage{}
+	for _, pod := range n.Pods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		if usage[pod.Spec.NodeName] == nil {
+			usage[pod.Spec.NodeName] = &nodeUsage{}
+		}
+		u := usage[pod.Spec.NodeName]
+		requests, limits := utils.PodRequestsAndLimits(pod.Spec)
+		u.pods++
+		u.cpuRequests.Add(*requests.Cpu())
+		u.memoryRequests.Add(*requests.Memory())
+		u.cpuLimits.Add(*limits.Cpu())
+		u.memoryLimits.Add(*limits.Memory())
+	}
+	return usage
+}
+
+// record builds the report row of a single Node.
+func (n *NodeHandler) record(node v1.Node, u *nodeUsage) []interface{} {
+	capacity := node.Status.Capacity
+	allocatable := node.Status.Allocatable
+
+	var conditions []string
+	for _, condition := range node.Status.Conditions {
+		conditions = append(conditions, fmt.Sprintf("%s=%s", condition.Type, condition.Status))
+	}
+	var taints []string
+	for _, taint := range node.Spec.Taints {
+		if taint.Value != "" {
+			taints = append(taints, fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect))
+		} else {
+			taints = append(taints, fmt.Sprintf("%s:%s", taint.Key, taint.Effect))
+		}
+	}
+
+	record := []interface{}{
+		node.Name,
+		node.Labels[zoneLabel],
+		node.Labels[instanceTypeLabel],
+		node.Status.NodeInfo.KubeletVersion,
+		strings.Join(conditions, ", "),
+		node.Spec.Unschedulable,
+		strings.Join(taints, ", "),
+		u.pods,
+		allocatable.Pods().Value(),
+		utils.CPUMillicores(*capacity.Cpu()),
+		utils.MemoryMiB(*capacity.Memory()),
+		utils.CPUMillicores(*allocatable.Cpu()),
+		utils.MemoryMiB(*allocatable.Memory()),
+		utils.CPUMillicores(u.cpuRequests),
+		utils.MemoryMiB(u.memoryRequests),
+		utils.CPUMillicores(u.cpuLimits),
+		utils.MemoryMiB(u.memoryLimits),
+		percentOf(u.cpuRequests, *allocatable.Cpu()),
+		percentOf(u.memoryRequests, *allocatable.Memory()),
+		percentOf(u.cpuLimits, *allocatable.Cpu()),
+		percentOf(u.memoryLimits, *allocatable.Memory()),
+	}
+	record = append(record, usageCells(utils.GetNodeUsage(n.clientset, node.Name), *allocatable.Cpu(), *allocatable.Memory())...)
+	return append(record, nodeCost(node))
+}
+
+// nodeCost returns the monthly cost of the capacity of a node at the rates of its instance type, or
+// nil unless --pricing is set.
+func nodeCost(node v1.Node) interface{} {
+	prices := utils.GetPriceTable()
+	if prices == nil {
+		return nil
+	}
+	return prices.InstanceTypeRates(node.Labels[instanceTypeLabel]).MonthlyCost(*node.Status.Capacity.Cpu(), *node.Status.Capacity.Memory())
+}
+
+// usageCells builds the usage columns of a Node row, left empty when usage is not reported.
+func usageCells(usage *utils.ResourceUsage, cpuAllocatable resource.Quantity, memoryAllocatable resource.Quantity) []interface{} {
+	if usage == nil {
+		return []interface{}{nil, nil, nil, nil}
+	}
+	return []interface{}{
+		utils.CPUMillicores(usage.CPU),
+		utils.MemoryMiB(usage.Memory),
+		percentOf(usage.CPU, cpuAllocatable),
+		percentOf(usage.Memory, memoryAllocatable),
+	}
+}
+
+// totalRecord builds the cluster-wide row summing every node.
+func (n *NodeHandler) totalRecord(usage map[string]*nodeUsage) []interface{} {
+	total := &nodeUsage{}
+	var allocatablePods int64
+	var cpuCapacity, memoryCapacity, cpuAllocatable, memoryAllocatable resource.Quantity
+	var nodeMetrics *utils.ResourceUsage
+	var cost interface{}
+	for _, node := range n.Nodes {
+		allocatablePods += node.Status.Allocatable.Pods().Value()
+		cpuCapacity.Add(*node.Status.Capacity.Cpu())
+		memoryCapacity.Add(*node.Status.Capacity.Memory())
+		cpuAllocatable.Add(*node.Status.Allocatable.Cpu())
+		memoryAllocatable.Add(*node.Status.Allocatable.Memory())
+		if u, ok := usage[node.Name]; ok {
+			total.pods += u.pods
+			total.cpuRequests.Add(u.cpuRequests)
+			total.memoryRequests.Add(u.memoryRequests)
+			total.cpuLimits.Add(u.cpuLimits)
+			total.memoryLimits.Add(u.memoryLimits)
+		}
+		if c, ok := nodeCost(node).(float64); ok {
+			total, _ := cost.(float64)
+			cost = roundCents(total + c)
+		}
+		if metrics := utils.GetNodeUsage(n.clientset, node.Name); metrics != nil {
+			if nodeMetrics == nil {
+				nodeMetrics = &utils.ResourceUsage{}
+			}
+			nodeMetrics.CPU.Add(metrics.CPU)
+			nodeMetrics.Memory.Add(metrics.Memory)
+		}
+	}
+
+	record := []interface{}{
+		allValue,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		total.pods,
+		allocatablePods,
+		utils.CPUMillicores(cpuCapacity),
+		utils.MemoryMiB(memoryCapacity),
+		utils.CPUMillicores(cpuAllocatable),
+		utils.MemoryMiB(memoryAllocatable),
+		utils.CPUMillicores(total.cpuRequests),
+		utils.MemoryMiB(total.memoryRequests),
+		utils.CPUMillicores(total.cpuLimits),
+		utils.MemoryMiB(total.memoryLimits),
+		percentOf(total.cpuRequests, cpuAllocatable),
+		percentOf(total.memoryRequests, memoryAllocatable),
+		percentOf(total.cpuLimits, cpuAllocatable),
+		percentOf(total.memoryLimits, memoryAllocatable),
+	}
+	record = append(record, usageCells(nodeMetrics, cpuAllocatable, memoryAllocatable)...)
+	return append(record, cost)
+}
+
+// percentOf returns a quantity as a percentage of another, rounded to one decimal, or nil when the total is zero.
+func percentOf(q resource.Quantity, total resource.Quantity) interface{} {
+	if total.IsZero() {
+		return nil
+	}
+	return math.Round(float64(q.MilliValue())/float64(total.MilliValue())*1000) / 10
+}
+
+// WriteReport writes the information of the fetched Nodes to a report section, followed by a
+// cluster-wide row named "All".
+func (n *NodeHandler) WriteReport(writer utils.ReportWriter, section string) error {
+	utils.Info("Writing Nodes data to report", zap.String("section", section))
+	if err := writer.AddSection(section, currencyHeaders(NodeHeaders, utils.GetPriceTable())); err != nil {
+		utils.Error("Failed to add Nodes section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+	usage := n.usage()
+	for _, node := range n.Nodes {
+		u, ok := usage[node.Name]
+		if !ok {
+			u = &nodeUsage{}
+		}
+		if err := writer.WriteRow(section, n.record(node, u)); err != nil {
+			utils.Error("Failed to write report row for Node", zap.String("nodeName", node.Name), zap.Error(err))
+			return err
+		}
+	}
+	if err := writer.WriteRow(section, n.totalRecord(usage)); err != nil {
+		utils.Error("Failed to write cluster-wide report row for Nodes", zap.Error(err))
+		return err
+	}
+	utils.Info("Successfully written Node data to report", zap.String("section", section))
+	return nil
+}
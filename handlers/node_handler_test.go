@@ -0,0 +1,33 @@
+// handlers/node_handler_test.go
+
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s-reporter/utils"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestUsageCells(t *testing.T) {
+	cpuAllocatable := resource.MustParse("2")
+	memoryAllocatable := resource.MustParse("4Gi")
+
+	if got, want := usageCells(nil, cpuAllocatable, memoryAllocatable), []interface{}{nil, nil, nil, nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("usage cells without usage are %v, want %v", got, want)
+	}
+
+	usage := &utils.ResourceUsage{CPU: resource.MustParse("500m"), Memory: resource.MustParse("1536Mi")}
+	want := []interface{}{int64(500), 1536.0, 25.0, 37.5}
+	if got := usageCells(usage, cpuAllocatable, memoryAllocatable); !reflect.DeepEqual(got, want) {
+		t.Errorf("usage cells are %v, want %v", got, want)
+	}
+
+	// Nodes without allocatable resources have no usage percentage.
+	want = []interface{}{int64(500), 1536.0, nil, nil}
+	if got := usageCells(usage, resource.Quantity{}, resource.Quantity{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("usage cells without allocatable resources are %v, want %v", got, want)
+	}
+}
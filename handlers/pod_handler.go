@@ -0,0 +1,149 @@
+// handlers/pod_handler.go
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"k8s-reporter/utils"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PodHandler is a struct that implements the ResourceHandler interface
+// for Kubernetes Pods. Unlike the other handlers it reports the live status
+// of the running pods rather than the spec of their controller.
+type PodHandler struct {
+	Pods      []v1.Pod
+	clientset kubernetes.Interface
+}
+
+var PodHeaders = []string{
+	"Name",
+	"Namespace",
+	"Workload",
+	"Phase",
+	"Status",
+	"Node",
+	"Pod IP",
+	"Ready Containers",
+	"Containers",
+	"Restarts",
+	"Container Restarts",
+	"Last Termination Reason",
+	"Start Time",
+	"Age",
+	"QoS Class",
+}
+
+// FetchResources fetches all Pods across all namespaces and stores them.
+func (p *PodHandler) FetchResources(clientset kubernetes.Interface) error {
+	utils.Info("Fetching Pods from Kubernetes cluster")
+	pods, err := clientset.CoreV1().Pods("").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		utils.Error("Failed to fetch Pods", zap.Error(err))
+		return err
+	}
+	p.Pods = pods.Items
+	p.clientset = clientset
+	utils.Info("Fetched Pods", zap.Int("count", len(p.Pods)))
+	return nil
+}
+
+// record builds the report row of a single Pod.
+func (p *PodHandler) record(pod v1.Pod) []interface{} {
+	var workload string
+	if kind, name, _ := utils.ResolveTopController(p.clientset, pod.ObjectMeta); kind != "" {
+		workload = fmt.Sprintf("%s/%s", kind, name)
+	}
+
+	var readyContainers, restarts int32
+	var containerRestarts, terminationReasons []string
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			readyContainers++
+		}
+		restarts += status.RestartCount
+		containerRestarts = append(containerRestarts, fmt.Sprintf("%s=%d", status.Name, status.RestartCount))
+		if terminated := status.LastTerminationState.Terminated; terminated != nil {
+			terminationReasons = append(terminationReasons, fmt.Sprintf("%s: %s", status.Name, terminated.Reason))
+		}
+	}
+
+	var startTime, age interface{}
+	if pod.Status.StartTime != nil {
+		startTime = pod.Status.StartTime.UTC().Format(time.RFC3339)
+	}
+	if !pod.CreationTimestamp.IsZero() {
+		age = duration.HumanDuration(time.Since(pod.CreationTimestamp.Time))
+	}
+
+	return []interface{}{
+		pod.Name,
+		pod.Namespace,
+		workload,
+		string(pod.Status.Phase),
+		podStatusReason(pod),
+		pod.Spec.NodeName,
+		pod.Status.PodIP,
+		readyContainers,
+		len(pod.Spec.Containers),
+		restarts,
+		strings.Join(containerRestarts, ", "),
+		strings.Join(terminationReasons, ", "),
+		startTime,
+		age,
+		string(pod.Status.QOSClass),
+	}
+}
+
+// podStatusReason returns the status of a pod the way kubectl shows it, e.g. CrashLoopBackOff,
+// OOMKilled or Terminating, falling back to the pod phase.
+func podStatusReason(pod v1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "" && status.State.Waiting.Reason != "PodInitializing" {
+			return "Init:" + status.State.Waiting.Reason
+		}
+		if status.State.Terminated != nil && status.State.Terminated.ExitCode != 0 {
+			return "Init:" + status.State.Terminated.Reason
+		}
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+			return status.State.Waiting.Reason
+		}
+		if status.State.Terminated != nil && status.State.Terminated.Reason != "" {
+			return status.State.Terminated.Reason
+		}
+	}
+	if pod.Status.Reason != "" {
+		return pod.Status.Reason
+	}
+	return string(pod.Status.Phase)
+}
+
+// WriteReport writes the information of the fetched Pods to a report section.
+func (p *PodHandler) WriteReport(writer utils.ReportWriter, section string) error {
+	utils.Info("Writing Pods data to report", zap.String("section", section))
+	if err := writer.AddSection(section, PodHeaders); err != nil {
+		utils.Error("Failed to add Pods section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+	for _, pod := range p.Pods {
+		if err := writer.WriteRow(section, p.record(pod)); err != nil {
+			utils.Error("Failed to write report row for Pod", zap.String("podName", pod.Name), zap.Error(err))
+			return err
+		}
+	}
+	utils.Info("Successfully written Pod data to report", zap.String("section", section))
+	return nil
+}
@@ -0,0 +1,278 @@
+// handlers/pod_security.go
+
+package handlers
+
+import (
+	"fmt"
+	"k8s-reporter/utils"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/pod-security-admission/api"
+	"k8s.io/pod-security-admission/policy"
+)
+
+// podSecurityCategory is the category of the findings about the Pod Security Standards.
+const podSecurityCategory = "pod-security"
+
+// podSecurityLevels are the Pod Security Standards levels, from the least to the most strict.
+var podSecurityLevels = []api.Level{api.LevelPrivileged, api.LevelBaseline, api.LevelRestricted}
+
+var (
+	podSecurityEvaluator     policy.Evaluator
+	podSecurityEvaluatorErr  error
+	podSecurityEvaluatorOnce sync.Once
+)
+
+var PodSecurityHeaders = []string{
+	"Kind",
+	"Namespace",
+	"Name",
+	"Highest Level",
+	"Baseline Violations",
+	"Restricted Violations",
+	"Namespace Enforce",
+	"Namespace Warn",
+	"Namespace Audit",
+	"Allowed By Enforce",
+	"Passes Warn",
+	"Passes Audit",
+}
+
+var NamespacePodSecurityHeaders = []string{
+	"Namespace",
+	"Workloads",
+	"Enforce",
+	"Warn",
+	"Audit",
+	"Privileged Workloads",
+	"Baseline Workloads",
+	"Restricted Workloads",
+	"Workloads Violating Enforce",
+	"Workloads Violating Warn",
+	"Workloads Violating Audit",
+	"Strictest Level Satisfied",
+	"Can Tighten Enforce To",
+}
+
+// podSecurity is the evaluation of the pod template of a workload against the Pod Security Standards.
+type podSecurity struct {
+	// level is the most strict level the workload satisfies, at the latest version of the standards.
+	level                api.Level
+	baselineViolations   []string
+	restrictedViolations []string
+	// policy is the policy of the namespace, from its pod-security.kubernetes.io labels.
+	policy api.Policy
+	// violations are the violations of the enforce, warn and audit levels of the namespace.
+	enforceViolations []string
+	warnViolations    []string
+	auditViolations   []string
+}
+
+// getPodSecurityEvaluator returns the evaluator of the checks of the Pod Security Standards, created on first use.
+func getPodSecurityEvaluator() (policy.Evaluator, error) {
+	podSecurityEvaluatorOnce.Do(func() {
+		podSecurityEvaluator, podSecurityEvaluatorErr = policy.NewEvaluator(policy.DefaultChecks())
+	})
+	if podSecurityEvaluatorErr != nil {
+		utils.Error("Failed to create Pod Security Standards evaluator", zap.Error(podSecurityEvaluatorErr))
+		return nil, podSecurityEvaluatorErr
+	}
+	return podSecurityEvaluator, nil
+}
+
+// podSecurityViolations evaluates the pod template of a workload at a level and version of the
+// Pod Security Standards, and returns the failed checks, worded like the PodSecurity admission plugin.
+func podSecurityViolations(evaluator policy.Evaluator, workload Workload, levelVersion api.LevelVersion) []string {
+	podMeta := &metav1.ObjectMeta{Annotations: workload.PodAnnotations}
+	podSpec := workload.PodSpec
+	var violations []string
+	for _, result := range evaluator.EvaluatePod(levelVersion, podMeta, &podSpec) {
+		if result.Allowed {
+			continue
+		}
+		if result.ForbiddenDetail != "" {
+			violations = append(violations, fmt.Sprintf("%s (%s)", result.ForbiddenReason, result.ForbiddenDetail))
+		} else {
+			violations = append(violations, result.ForbiddenReason)
+		}
+	}
+	return violations
+}
+
+// evaluatePodSecurity evaluates a workload against the Baseline and Restricted levels and against the
+// levels its namespace enforces, warns about and audits. Namespaces without labels are privileged.
+func evaluatePodSecurity(workload Workload) (podSecurity, error) {
+	evaluator, err := getPodSecurityEvaluator()
+	if err != nil {
+		return podSecurity{}, err
+	}
+	latest := api.LatestVersion()
+	evaluation := podSecurity{
+		baselineViolations:   podSecurityViolations(evaluator, workload, api.LevelVersion{Level: api.LevelBaseline, Version: latest}),
+		restrictedViolations: podSecurityViolations(evaluator, workload, api.LevelVersion{Level: api.LevelRestricted, Version: latest}),
+	}
+	switch {
+	case len(evaluation.restrictedViolations) == 0:
+		evaluation.level = api.LevelRestricted
+	case len(evaluation.baselineViolations) == 0:
+		evaluation.level = api.LevelBaseline
+	default:
+		evaluation.level = api.LevelPrivileged
+	}
+
+	privileged := api.LevelVersion{Level: api.LevelPrivileged, Version: latest}
+	namespacePolicy, errs := api.PolicyToEvaluate(workload.NamespaceLabels, api.Policy{Enforce: privileged, Warn: privileged, Audit: privileged})
+	if len(errs) > 0 {
+		utils.Warn("Invalid Pod Security labels on namespace", zap.String("namespace", workload.Namespace), zap.Error(errs.ToAggregate()))
+	}
+	evaluation.policy = namespacePolicy
+	evaluation.enforceViolations = podSecurityViolations(evaluator, workload, namespacePolicy.Enforce)
+	evaluation.warnViolations = podSecurityViolations(evaluator, workload, namespacePolicy.Warn)
+	evaluation.auditViolations = podSecurityViolations(evaluator, workload, namespacePolicy.Audit)
+	return evaluation, nil
+}
+
+// checkPodSecurity returns a finding for every level of its namespace a workload violates: its pods
+// would be rejected (enforce), or trigger warnings or audit annotations (warn, audit).
+func checkPodSecurity(workload Workload) ([]Finding, error) {
+	evaluation, err := evaluatePodSecurity(workload)
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	modes := []struct {
+		mode         string
+		severity     string
+		levelVersion api.LevelVersion
+		violations   []string
+	}{
+		{"enforce", utils.SeverityError, evaluation.policy.Enforce, evaluation.enforceViolations},
+		{"warn", utils.SeverityWarning, evaluation.policy.Warn, evaluation.warnViolations},
+		{"audit", utils.SeverityWarning, evaluation.policy.Audit, evaluation.auditViolations},
+	}
+	for _, mode := range modes {
+		if len(mode.violations) == 0 {
+			continue
+		}
+		findings = append(findings, newFinding(workload, "", podSecurityCategory, "pod-security-"+mode.mode, mode.severity,
+			fmt.Sprintf("Pods violate the %s Pod Security level %s of namespace %s: %s",
+				mode.mode, mode.levelVersion.String(), workload.Namespace, strings.Join(mode.violations, ", "))))
+	}
+	return findings, nil
+}
+
+// podSecurityRecord builds the report row of the Pod Security evaluation of a single workload.
+func podSecurityRecord(workload Workload, evaluation podSecurity) []interface{} {
+	return []interface{}{
+		workload.Kind,
+		workload.Namespace,
+		workload.Name,
+		string(evaluation.level),
+		strings.Join(evaluation.baselineViolations, ", "),
+		strings.Join(evaluation.restrictedViolations, ", "),
+		evaluation.policy.Enforce.String(),
+		evaluation.policy.Warn.String(),
+		evaluation.policy.Audit.String(),
+		len(evaluation.enforceViolations) == 0,
+		len(evaluation.warnViolations) == 0,
+		len(evaluation.auditViolations) == 0,
+	}
+}
+
+// namespacePodSecurity aggregates the Pod Security evaluations of the workloads of a namespace.
+type namespacePodSecurity struct {
+	workloads         int
+	policy            api.Policy
+	levels            map[api.Level]int
+	enforceViolations int
+	warnViolations    int
+	auditViolations   int
+	// strictest is the most strict level satisfied by every workload of the namespace.
+	strictest api.Level
+}
+
+func (s *namespacePodSecurity) add(evaluation podSecurity) {
+	if s.workloads == 0 || api.CompareLevels(evaluation.level, s.strictest) < 0 {
+		s.strictest = evaluation.level
+	}
+	s.workloads++
+	s.policy = evaluation.policy
+	s.levels[evaluation.level]++
+	if len(evaluation.enforceViolations) > 0 {
+		s.enforceViolations++
+	}
+	if len(evaluation.warnViolations) > 0 {
+		s.warnViolations++
+	}
+	if len(evaluation.auditViolations) > 0 {
+		s.auditViolations++
+	}
+}
+
+func (s *namespacePodSecurity) record(namespace string) []interface{} {
+	// The namespace could enforce a stricter level without breaking any of its workloads.
+	var tighten interface{}
+	if api.CompareLevels(s.strictest, s.policy.Enforce.Level) > 0 {
+		tighten = string(s.strictest)
+	}
+	record := []interface{}{
+		namespace,
+		s.workloads,
+		s.policy.Enforce.String(),
+		s.policy.Warn.String(),
+		s.policy.Audit.String(),
+	}
+	for _, level := range podSecurityLevels {
+		record = append(record, s.levels[level])
+	}
+	return append(record,
+		s.enforceViolations,
+		s.warnViolations,
+		s.auditViolations,
+		string(s.strictest),
+		tighten,
+	)
+}
+
+// WritePodSecurity writes the Pod Security Standards evaluation of the given workloads: one row per
+// workload in the first section, and one row per namespace in the second, comparing the levels its
+// workloads satisfy to the levels of its pod-security.kubernetes.io labels.
+func WritePodSecurity(writer utils.ReportWriter, section string, namespaceSection string, workloads []Workload) error {
+	utils.Info("Writing Pod Security evaluation to report", zap.String("section", section))
+	if err := writer.AddSection(section, PodSecurityHeaders); err != nil {
+		utils.Error("Failed to add Pod Security section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+
+	namespaces := map[string]*namespacePodSecurity{}
+	for _, workload := range workloads {
+		evaluation, err := evaluatePodSecurity(workload)
+		if err != nil {
+			return err
+		}
+		if namespaces[workload.Namespace] == nil {
+			namespaces[workload.Namespace] = &namespacePodSecurity{levels: map[api.Level]int{}}
+		}
+		namespaces[workload.Namespace].add(evaluation)
+		if err := writer.WriteRow(section, podSecurityRecord(workload, evaluation)); err != nil {
+			utils.Error("Failed to write report row for Pod Security evaluation", zap.String("kind", workload.Kind), zap.String("name", workload.Name), zap.Error(err))
+			return err
+		}
+	}
+
+	if err := writer.AddSection(namespaceSection, NamespacePodSecurityHeaders); err != nil {
+		utils.Error("Failed to add Namespace Pod Security section to report", zap.String("section", namespaceSection), zap.Error(err))
+		return err
+	}
+	for _, namespace := range sortedKeys(namespaces) {
+		if err := writer.WriteRow(namespaceSection, namespaces[namespace].record(namespace)); err != nil {
+			utils.Error("Failed to write report row for namespace Pod Security", zap.String("namespace", namespace), zap.Error(err))
+			return err
+		}
+	}
+	utils.Info("Successfully written Pod Security evaluation to report", zap.String("section", section))
+	return nil
+}
@@ -0,0 +1,121 @@
+// handlers/policy.go
+
+package handlers
+
+import (
+	"fmt"
+	"k8s-reporter/utils"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// policyCategory is the category of the findings of the custom rules of the --rules file.
+const policyCategory = "policy"
+
+// policyObject converts the Kubernetes resource of a workload to the "object" variable of the rules,
+// the resource as it would be serialized, e.g. object.spec.template.spec.containers.
+func policyObject(workload Workload) (map[string]interface{}, error) {
+	if workload.Object == nil {
+		return map[string]interface{}{}, nil
+	}
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(workload.Object)
+	if err != nil {
+		return nil, err
+	}
+	// Typed objects listed by client-go have no apiVersion and kind.
+	if gvks, _, err := scheme.Scheme.ObjectKinds(workload.Object); err == nil && len(gvks) > 0 {
+		object["apiVersion"], object["kind"] = gvks[0].GroupVersion().String(), gvks[0].Kind
+	}
+	return object, nil
+}
+
+// policyWorkload returns the "workload" variable of the rules: the fields computed by k8s-reporter,
+// in the units of the report columns (CPU in millicores, memory in MiB).
+func policyWorkload(workload Workload) map[string]interface{} {
+	resources := workload.Resources
+	images := []interface{}{}
+	for _, image := range utils.ExtractContainerImages(workload.PodSpec) {
+		images = append(images, map[string]interface{}{
+			"container":  image.Container,
+			"type":       image.Type,
+			"image":      image.Image,
+			"pullPolicy": string(image.PullPolicy),
+			"registry":   image.Reference.Registry,
+			"repository": image.Reference.Repository,
+			"tag":        image.Reference.Tag,
+			"digest":     image.Reference.Digest,
+			"latest":     image.Err == nil && image.Reference.Latest(),
+			"untagged":   image.Err == nil && image.Reference.Untagged(),
+		})
+	}
+	limitRangeViolations := []interface{}{}
+	for _, violation := range resources.LimitRangeViolations {
+		limitRangeViolations = append(limitRangeViolations, violation)
+	}
+	return map[string]interface{}{
+		"kind":                 workload.Kind,
+		"name":                 workload.Name,
+		"namespace":            workload.Namespace,
+		"replicas":             int64(workload.Replicas),
+		"cpuRequests":          utils.CPUMillicores(resources.CPURequests),
+		"cpuLimits":            utils.CPUMillicores(resources.CPULimits),
+		"memoryRequests":       utils.MemoryMiB(resources.MemoryRequests),
+		"memoryLimits":         utils.MemoryMiB(resources.MemoryLimits),
+		"totalCpuRequests":     utils.CPUMillicores(workload.TotalCPURequests()),
+		"totalCpuLimits":       utils.CPUMillicores(workload.TotalCPULimits()),
+		"totalMemoryRequests":  utils.MemoryMiB(workload.TotalMemoryRequests()),
+		"totalMemoryLimits":    utils.MemoryMiB(workload.TotalMemoryLimits()),
+		"qosClass":             resources.QoSClass,
+		"defaultsApplied":      resources.DefaultsApplied,
+		"limitRangeViolations": limitRangeViolations,
+		"memoryReadiness":      resources.MemoryReadiness(),
+		"images":               images,
+		"podAnnotations":       stringMap(workload.PodAnnotations),
+		"namespaceLabels":      stringMap(workload.NamespaceLabels),
+	}
+}
+
+// stringMap returns a map usable by the rules, never nil so that "key in map" works.
+func stringMap(values map[string]string) map[string]string {
+	if values == nil {
+		return map[string]string{}
+	}
+	return values
+}
+
+// checkPolicies evaluates the custom rules of the --rules file on a workload, and returns a finding for
+// every rule it does not satisfy. A rule failing to evaluate, e.g. on a missing field, is a warning.
+func checkPolicies(workload Workload) []Finding {
+	rules := utils.GetRules()
+	if len(rules) == 0 {
+		return nil
+	}
+	object, err := policyObject(workload)
+	if err != nil {
+		utils.Warn("Failed to convert workload for the rules", zap.String("kind", workload.Kind), zap.String("name", workload.Name), zap.Error(err))
+		object = map[string]interface{}{}
+	}
+	variables := map[string]interface{}{
+		utils.ObjectVariable:   object,
+		utils.WorkloadVariable: policyWorkload(workload),
+	}
+
+	var findings []Finding
+	for _, rule := range rules {
+		if !rule.AppliesTo(workload.Kind) {
+			continue
+		}
+		compliant, err := rule.Evaluate(variables)
+		switch {
+		case err != nil:
+			utils.Warn("Failed to evaluate rule", zap.String("rule", rule.ID), zap.String("kind", workload.Kind), zap.String("name", workload.Name), zap.Error(err))
+			findings = append(findings, newFinding(workload, "", policyCategory, rule.ID, utils.SeverityWarning,
+				fmt.Sprintf("Rule %s could not be evaluated: %v", rule.ID, err)))
+		case !compliant:
+			findings = append(findings, newFinding(workload, "", policyCategory, rule.ID, rule.Severity, rule.Message))
+		}
+	}
+	return findings
+}
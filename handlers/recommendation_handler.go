@@ -0,0 +1,323 @@
+// handlers/recommendation_handler.go
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"k8s-reporter/utils"
+	"math"
+	"regexp"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+)
+
+// recommendationResolution is the rate window and the step of the subqueries computing CPU percentiles.
+const recommendationResolution = 5 * time.Minute
+
+// Smallest requests ever recommended, so that idle containers still get scheduled sensibly.
+var (
+	minRecommendedCPU    = resource.MustParse("10m")
+	minRecommendedMemory = resource.MustParse("16Mi")
+)
+
+// RecommendationHandler is a struct that implements the ResourceHandler interface
+// for right-sizing recommendations: it compares the requests and limits of every
+// container of the workloads to their usage over a window, read from Prometheus.
+type RecommendationHandler struct {
+	Prometheus *utils.PrometheusClient
+	// Window is how far back usage is looked at.
+	Window time.Duration
+	// Headroom is the percentage added on top of the observed usage.
+	Headroom float64
+	Targets  []RecommendationTarget
+}
+
+// RecommendationTarget is a workload whose containers get a recommendation.
+type RecommendationTarget struct {
+	Workload
+	Containers []v1.Container
+	// Observed is the usage of every container of the workload, by container name.
+	Observed map[string]*ContainerUsage
+	podName  *regexp.Regexp
+}
+
+// ContainerUsage is the historical usage of a container, over all the pods of its workload.
+type ContainerUsage struct {
+	Pods      map[string]bool
+	CPUP95    float64
+	CPUP99    float64
+	MemoryMax float64
+}
+
+var RecommendationHeaders = []string{
+	"Kind",
+	"Namespace",
+	"Name",
+	"Container",
+	"Replicas",
+	"Pods Observed",
+	"CPU Requests (m)",
+	"CPU Limits (m)",
+	"Memory Requests (MiB)",
+	"Memory Limits (MiB)",
+	"CPU P95 (m)",
+	"CPU P99 (m)",
+	"Memory Max (MiB)",
+	"Recommended CPU Requests (m)",
+	"Recommended CPU Limits (m)",
+	"Recommended Memory Requests (MiB)",
+	"Recommended Memory Limits (MiB)",
+	"CPU Requests Savings (m)",
+	"Memory Requests Savings (MiB)",
+}
+
+// generatedNameChars are the characters of the random suffixes and pod template hashes in the names
+// Kubernetes generates. They leave out vowels, so that e.g. the "api" of the pods of "web-api" is not
+// taken for a suffix of "web".
+const generatedNameChars = "[bcdfghjklmnpqrstvwxz2456789]"
+
+// podNamePattern matches the names of the pods a controller of the given kind creates, e.g.
+// <deployment>-<pod-template-hash>-<suffix> or <statefulset>-<ordinal>. Pods are matched by name
+// rather than by ownerReferences so that the pods that no longer exist are accounted for too.
+func podNamePattern(kind string, name string) *regexp.Regexp {
+	name = regexp.QuoteMeta(name)
+	suffix := generatedNameChars + "{5}"
+	switch kind {
+	case "Deployment":
+		return regexp.MustCompile(fmt.Sprintf(`^%s-%s{1,10}-%s$`, name, generatedNameChars, suffix))
+	case "StatefulSet":
+		return regexp.MustCompile(fmt.Sprintf(`^%s-[0-9]+$`, name))
+	case "CronJob":
+		// <cronjob>-<scheduled time>-<suffix>, or <cronjob>-<scheduled time>-<index>-<suffix> for Indexed Jobs
+		return regexp.MustCompile(fmt.Sprintf(`^%s-[0-9]+-([0-9]+-)?%s$`, name, suffix))
+	case "Job":
+		return regexp.MustCompile(fmt.Sprintf(`^%s-([0-9]+-)?%s$`, name, suffix))
+	default:
+		return regexp.MustCompile(fmt.Sprintf(`^%s-%s$`, name, suffix))
+	}
+}
+
+// FetchResources fetches the Deployments, DaemonSets, StatefulSets, Jobs and CronJobs, then
+// the usage of their containers over the window from Prometheus.
+func (r *RecommendationHandler) FetchResources(clientset kubernetes.Interface) error {
+	deployments := &DeploymentHandler{}
+	daemonSets := &DaemonSetHandler{}
+	statefulsets := &StatefulsetHandler{}
+	jobs := &JobHandler{}
+	cronJobs := &CronJobHandler{}
+	for _, handler := range []ResourceHandler{deployments, daemonSets, statefulsets, jobs, cronJobs} {
+		if err := handler.FetchResources(clientset); err != nil {
+			return err
+		}
+	}
+
+	r.Targets = nil
+	for _, deployment := range deployments.Deployments {
+		r.addTarget(deployments.workload(deployment), clientset)
+	}
+	for _, ds := range daemonSets.DaemonSets {
+		r.addTarget(daemonSets.workload(ds), clientset)
+	}
+	for _, statefulset := range statefulsets.Statefulsets {
+		r.addTarget(statefulsets.workload(statefulset), clientset)
+	}
+	for _, job := range jobs.Jobs {
+		// The pods of the Jobs of a CronJob get the recommendation of their CronJob.
+		if spawnedByCronJob(&job) {
+			continue
+		}
+		r.addTarget(jobs.workload(job), clientset)
+	}
+	for _, cronJob := range cronJobs.CronJobs {
+		workload := cronJobs.workload(cronJob)
+		workload.Replicas = 1
+		if parallelism := cronJob.Spec.JobTemplate.Spec.Parallelism; parallelism != nil {
+			workload.Replicas = *parallelism
+		}
+		r.addTarget(workload, clientset)
+	}
+
+	return r.fetchUsage()
+}
+
+// addTarget adds a workload and its running containers to the targets of the recommendations.
+func (r *RecommendationHandler) addTarget(workload Workload, clientset kubernetes.Interface) {
+	r.Targets = append(r.Targets, RecommendationTarget{
+		Workload:   workload,
+		Containers: utils.RunningContainers(clientset, workload.PodSpec, workload.Namespace),
+		Observed:   map[string]*ContainerUsage{},
+		podName:    podNamePattern(workload.Kind, workload.Name),
+	})
+}
+
+// fetchUsage queries the CPU percentiles and the peak memory of every container over the window,
+// and attributes them to the targets owning their pods.
+func (r *RecommendationHandler) fetchUsage() error {
+	window := utils.PrometheusDuration(r.Window)
+	resolution := utils.PrometheusDuration(recommendationResolution)
+	const selector = `{container!="", container!="POD"}`
+	cpuQuery := func(quantile float64) string {
+		return fmt.Sprintf(`quantile_over_time(%g, sum by (namespace, pod, container) (rate(container_cpu_usage_seconds_total%s[%s]))[%s:%s])`, quantile, selector, resolution, window, resolution)
+	}
+	queries := []struct {
+		query  string
+		update func(usage *ContainerUsage, value float64)
+	}{
+		{cpuQuery(0.95), func(usage *ContainerUsage, value float64) { usage.CPUP95 = math.Max(usage.CPUP95, value) }},
+		{cpuQuery(0.99), func(usage *ContainerUsage, value float64) { usage.CPUP99 = math.Max(usage.CPUP99, value) }},
+		{
+			fmt.Sprintf(`max by (namespace, pod, container) (max_over_time(container_memory_working_set_bytes%s[%s]))`, selector, window),
+			func(usage *ContainerUsage, value float64) { usage.MemoryMax = math.Max(usage.MemoryMax, value) },
+		},
+	}
+
+	byNamespace := map[string][]int{}
+	for i, target := range r.Targets {
+		byNamespace[target.Namespace] = append(byNamespace[target.Namespace], i)
+	}
+
+	for _, query := range queries {
+		utils.Info("Querying container usage from Prometheus", zap.String("query", query.query))
+		samples, err := r.Prometheus.Query(context.Background(), query.query)
+		if err != nil {
+			utils.Error("Failed to query container usage from Prometheus", zap.String("query", query.query), zap.Error(err))
+			return err
+		}
+		for _, sample := range samples {
+			target := r.targetOf(byNamespace[sample.Metric["namespace"]], sample.Metric["pod"])
+			if target == nil {
+				continue
+			}
+			container := sample.Metric["container"]
+			if target.Observed[container] == nil {
+				target.Observed[container] = &ContainerUsage{Pods: map[string]bool{}}
+			}
+			target.Observed[container].Pods[sample.Metric["pod"]] = true
+			query.update(target.Observed[container], sample.Value)
+		}
+		utils.Info("Fetched container usage from Prometheus", zap.Int("count", len(samples)))
+	}
+	return nil
+}
+
+// targetOf returns the target whose pods are named like the given pod. When several match, e.g.
+// the Deployments "web" and "web-api", the one with the longest name is the most specific.
+func (r *RecommendationHandler) targetOf(candidates []int, pod string) *RecommendationTarget {
+	var match *RecommendationTarget
+	for _, i := range candidates {
+		target := &r.Targets[i]
+		if target.podName.MatchString(pod) && (match == nil || len(target.Name) > len(match.Name)) {
+			match = target
+		}
+	}
+	return match
+}
+
+// recommend adds the headroom to an observed value and rounds it up to the given unit, never going
+// below the given minimum.
+func (r *RecommendationHandler) recommend(observed float64, unit float64, minimum resource.Quantity, format resource.Format) resource.Quantity {
+	value := math.Ceil(observed*(1+r.Headroom/100)/unit) * unit
+	if value < minimum.AsApproximateFloat64() {
+		return minimum.DeepCopy()
+	}
+	if format == resource.DecimalSI {
+		return *resource.NewMilliQuantity(int64(math.Round(value*1000)), format)
+	}
+	return *resource.NewQuantity(int64(value), format)
+}
+
+// record builds the report row of a single container, and returns the savings of its recommendation
+// over all the replicas of its workload. The recommendation is left empty when no usage was observed.
+func (r *RecommendationHandler) record(target RecommendationTarget, container v1.Container) (record []interface{}, cpuSavings resource.Quantity, memorySavings resource.Quantity) {
+	requests := container.Resources.Requests
+	limits := container.Resources.Limits
+	observed := target.Observed[container.Name]
+
+	record = []interface{}{
+		target.Kind,
+		target.Namespace,
+		target.Name,
+		container.Name,
+		target.Replicas,
+		0,
+		utils.CPUMillicores(*requests.Cpu()),
+		utils.CPUMillicores(*limits.Cpu()),
+		utils.MemoryMiB(*requests.Memory()),
+		utils.MemoryMiB(*limits.Memory()),
+	}
+	if observed == nil {
+		return append(record, make([]interface{}, len(RecommendationHeaders)-len(record))...), cpuSavings, memorySavings
+	}
+
+	record[5] = len(observed.Pods)
+	cpuRequests := r.recommend(observed.CPUP95, 0.001, minRecommendedCPU, resource.DecimalSI)
+	cpuLimits := r.recommend(observed.CPUP99, 0.001, minRecommendedCPU, resource.DecimalSI)
+	memory := r.recommend(observed.MemoryMax, 1024*1024, minRecommendedMemory, resource.BinarySI)
+
+	cpuSavings = requests.Cpu().DeepCopy()
+	cpuSavings.Sub(cpuRequests)
+	cpuSavings = utils.MultiplyQuantity(cpuSavings, target.Replicas)
+	memorySavings = requests.Memory().DeepCopy()
+	memorySavings.Sub(memory)
+	memorySavings = utils.MultiplyQuantity(memorySavings, target.Replicas)
+
+	return append(record,
+		int64(math.Round(observed.CPUP95*1000)),
+		int64(math.Round(observed.CPUP99*1000)),
+		math.Round(observed.MemoryMax/(1024*1024)*100)/100,
+		utils.CPUMillicores(cpuRequests),
+		utils.CPUMillicores(cpuLimits),
+		utils.MemoryMiB(memory),
+		utils.MemoryMiB(memory),
+		utils.CPUMillicores(cpuSavings),
+		utils.MemoryMiB(memorySavings),
+	), cpuSavings, memorySavings
+}
+
+// WriteReport writes one row per container of the targets to a report section, followed by a row
+// named "All" with the total savings. Negative savings mean the container needs more than it requests.
+func (r *RecommendationHandler) WriteReport(writer utils.ReportWriter, section string) error {
+	utils.Info("Writing Recommendations data to report", zap.String("section", section))
+	if err := writer.AddSection(section, RecommendationHeaders); err != nil {
+		utils.Error("Failed to add Recommendations section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+
+	targets := append([]RecommendationTarget{}, r.Targets...)
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].Namespace != targets[j].Namespace {
+			return targets[i].Namespace < targets[j].Namespace
+		}
+		return targets[i].Name < targets[j].Name
+	})
+
+	var totalCPUSavings, totalMemorySavings resource.Quantity
+	for _, target := range targets {
+		for _, container := range target.Containers {
+			record, cpuSavings, memorySavings := r.record(target, container)
+			totalCPUSavings.Add(cpuSavings)
+			totalMemorySavings.Add(memorySavings)
+			if err := writer.WriteRow(section, record); err != nil {
+				utils.Error("Failed to write report row for Recommendation", zap.String("name", target.Name), zap.String("containerName", container.Name), zap.Error(err))
+				return err
+			}
+		}
+	}
+
+	total := make([]interface{}, len(RecommendationHeaders))
+	total[0] = allValue
+	total[len(total)-2] = utils.CPUMillicores(totalCPUSavings)
+	total[len(total)-1] = utils.MemoryMiB(totalMemorySavings)
+	if err := writer.WriteRow(section, total); err != nil {
+		utils.Error("Failed to write total savings row for Recommendations", zap.Error(err))
+		return err
+	}
+	utils.Info("Successfully written Recommendation data to report", zap.String("section", section))
+	return nil
+}
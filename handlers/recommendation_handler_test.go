@@ -0,0 +1,192 @@
+// handlers/recommendation_handler_test.go
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-reporter/utils"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestPodNamePattern(t *testing.T) {
+	tests := []struct {
+		kind  string
+		name  string
+		pod   string
+		match bool
+	}{
+		{"Deployment", "web", "web-7c9d6b5f4-x7k2p", true},
+		{"Deployment", "web", "web-api-7c9d6b5f4-x7k2p", false},
+		// a DaemonSet "web-api" has pods named like those of a Deployment "web" with a hash of "api"
+		{"Deployment", "web", "web-api-x7k2p", false},
+		{"Deployment", "web", "web-7c9d6b5f4", false},
+		{"Deployment", "web.v2", "webxv2-7c9d6b5f4-x7k2p", false},
+		{"StatefulSet", "db", "db-0", true},
+		{"StatefulSet", "db", "db-12", true},
+		{"StatefulSet", "db", "db-replica-0", false},
+		{"DaemonSet", "agent", "agent-x7k2p", true},
+		{"DaemonSet", "agent", "agent-api-x7k2p", false},
+		{"DaemonSet", "agent", "agent-api", false},
+		{"Job", "migrate", "migrate-x7k2p", true},
+		{"Job", "migrate", "migrate-3-x7k2p", true},
+		{"Job", "migrate", "migrate-data-x7k2p", false},
+		{"CronJob", "backup", "backup-28000000-x7k2p", true},
+		{"CronJob", "backup", "backup-28000000-2-x7k2p", true},
+		{"CronJob", "backup", "backup-x7k2p", false},
+	}
+	for _, test := range tests {
+		if got := podNamePattern(test.kind, test.name).MatchString(test.pod); got != test.match {
+			t.Errorf("pattern of %s %q matches %q: %v, want %v", test.kind, test.name, test.pod, got, test.match)
+		}
+	}
+}
+
+// recommendationTarget returns a target for a workload whose single container "app" requests
+// 500m of CPU and 256MiB of memory.
+func recommendationTarget(kind string, namespace string, name string, replicas int32) RecommendationTarget {
+	requests := v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("256Mi")}
+	return RecommendationTarget{
+		Workload:   Workload{Kind: kind, Namespace: namespace, Name: name, Replicas: replicas},
+		Containers: []v1.Container{{Name: "app", Resources: v1.ResourceRequirements{Requests: requests, Limits: requests}}},
+		Observed:   map[string]*ContainerUsage{},
+		podName:    podNamePattern(kind, name),
+	}
+}
+
+func TestTargetOf(t *testing.T) {
+	r := &RecommendationHandler{Targets: []RecommendationTarget{
+		recommendationTarget("Deployment", "shop", "web", 1),
+		recommendationTarget("Deployment", "shop", "web-api", 1),
+		recommendationTarget("StatefulSet", "shop", "web-api-db", 1),
+	}}
+	candidates := []int{0, 1, 2}
+	for pod, want := range map[string]string{
+		"web-7c9d6b5f4-x7k2p":     "web",
+		"web-api-7c9d6b5f4-x7k2p": "web-api",
+		"web-api-db-0":            "web-api-db",
+		"web-api-x7k2p":           "",
+		"cart-7c9d6b5f4-x7k2p":    "",
+	} {
+		got := ""
+		if target := r.targetOf(candidates, pod); target != nil {
+			got = target.Name
+		}
+		if got != want {
+			t.Errorf("pod %q is matched to %q, want %q", pod, got, want)
+		}
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	r := &RecommendationHandler{Headroom: 15}
+	tests := []struct {
+		observed float64
+		unit     float64
+		minimum  resource.Quantity
+		format   resource.Format
+		want     string
+	}{
+		// 0.2 * 1.15 = 0.23, despite the floating point error
+		{0.2, 0.001, minRecommendedCPU, resource.DecimalSI, "230m"},
+		// 0.3001 * 1.15 = 0.345115, rounded up to the millicore
+		{0.3001, 0.001, minRecommendedCPU, resource.DecimalSI, "346m"},
+		{0.001, 0.001, minRecommendedCPU, resource.DecimalSI, "10m"},
+		{0, 0.001, minRecommendedCPU, resource.DecimalSI, "10m"},
+		// 100.5Mi * 1.15 = 115.575Mi, rounded up to the MiB
+		{100.5 * 1024 * 1024, 1024 * 1024, minRecommendedMemory, resource.BinarySI, "116Mi"},
+		{1024 * 1024, 1024 * 1024, minRecommendedMemory, resource.BinarySI, "16Mi"},
+	}
+	for _, test := range tests {
+		got := r.recommend(test.observed, test.unit, test.minimum, test.format)
+		if want := resource.MustParse(test.want); got.Cmp(want) != 0 {
+			t.Errorf("recommend(%v) = %s, want %s", test.observed, got.String(), test.want)
+		}
+	}
+}
+
+// usageServer stands in for the query API of Prometheus, answering the CPU p95, CPU p99 and peak
+// memory queries with the given series of the pods of the "shop" namespace, by pod name.
+func usageServer(t *testing.T, cpuP95 map[string]float64, cpuP99 map[string]float64, memory map[string]float64) *utils.PrometheusClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.FormValue("query")
+		values := memory
+		switch {
+		case strings.HasPrefix(query, "quantile_over_time(0.95,"):
+			values = cpuP95
+		case strings.HasPrefix(query, "quantile_over_time(0.99,"):
+			values = cpuP99
+		}
+		var series []string
+		for pod, value := range values {
+			series = append(series, fmt.Sprintf(`{"metric":{"namespace":"shop","pod":%q,"container":"app"},"value":[1700000000,"%g"]}`, pod, value))
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[%s]}}`, strings.Join(series, ","))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := utils.NewPrometheusClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewPrometheusClient: %v", err)
+	}
+	return client
+}
+
+func TestRecommendationReport(t *testing.T) {
+	const mib = 1024 * 1024
+	prometheus := usageServer(t,
+		map[string]float64{"web-7c9d6b5f4-x7k2p": 0.2, "web-7c9d6b5f4-b4n8q": 0.15, "web-api-7c9d6b5f4-x7k2p": 2, "gone-x7k2p": 1},
+		map[string]float64{"web-7c9d6b5f4-x7k2p": 0.3001, "web-7c9d6b5f4-b4n8q": 0.25, "web-api-7c9d6b5f4-x7k2p": 2},
+		map[string]float64{"web-7c9d6b5f4-x7k2p": 100.5 * mib, "web-7c9d6b5f4-b4n8q": 80 * mib, "web-api-7c9d6b5f4-x7k2p": 300 * mib},
+	)
+	r := &RecommendationHandler{
+		Prometheus: prometheus,
+		Window:     7 * 24 * time.Hour,
+		Headroom:   15,
+		Targets: []RecommendationTarget{
+			recommendationTarget("Deployment", "shop", "web", 2),
+			recommendationTarget("Deployment", "shop", "web-api", 1),
+			recommendationTarget("Deployment", "shop", "cart", 3),
+		},
+	}
+	if err := r.fetchUsage(); err != nil {
+		t.Fatalf("fetchUsage: %v", err)
+	}
+
+	writer := &recordingWriter{}
+	if err := r.WriteReport(writer, "Recommendations"); err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+	rows := writer.rows["Recommendations"]
+	if len(rows) != 4 {
+		t.Fatalf("Recommendations section has %d rows, want 3 containers and All", len(rows))
+	}
+
+	// rows are sorted by name: cart, web, web-api
+	cart, web, webAPI, all := rows[0], rows[1], rows[2], rows[3]
+	if cart[2] != "cart" || cart[5] != 0 || cart[13] != nil {
+		t.Errorf("cart has no usage and no recommendation, got %v", cart)
+	}
+	// CPU P95, CPU P99, Memory Max, then the recommended CPU requests and limits, memory requests and
+	// limits, and the savings over the 2 replicas
+	want := []interface{}{"web", 2, int64(200), int64(300), 100.5, int64(230), int64(346), 116.0, 116.0, int64(540), 280.0}
+	got := []interface{}{web[2], web[5], web[10], web[11], web[12], web[13], web[14], web[15], web[16], web[17], web[18]}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("web recommendation is %v, want %v", got, want)
+	}
+	// web-api needs more than it requests, its savings are negative
+	if webAPI[2] != "web-api" || webAPI[5] != 1 || webAPI[13] != int64(2300) || webAPI[17] != int64(-1800) {
+		t.Errorf("web-api recommendation is %v", webAPI)
+	}
+	if all[0] != allValue || all[17] != int64(540-1800) || all[18] != 280.0+256-345 {
+		t.Errorf("total savings are %v", all)
+	}
+}
@@ -0,0 +1,121 @@
+// handlers/sarif.go
+
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"k8s-reporter/utils"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// sarifFingerprint is the name of the partial fingerprint identifying a finding across runs.
+const sarifFingerprint = "k8sReporterFinding/v1"
+
+// sarifLevels maps the severities of the findings to SARIF levels.
+var sarifLevels = map[string]string{
+	utils.SeverityError:   "error",
+	utils.SeverityWarning: "warning",
+	utils.SeverityInfo:    "note",
+}
+
+// ruleDescriptions describe the built-in rules. Custom rules are described by their message, and
+// vulnerabilities by their ID.
+var ruleDescriptions = map[string]string{
+	privilegedRule:                   "Containers must not be privileged",
+	runAsRootRule:                    "Containers must not run as root",
+	privilegeEscalationRule:          "Containers must not allow privilege escalation",
+	addedCapabilitiesRule:            "Containers must not add capabilities",
+	hostNetworkRule:                  "Pods must not use the network namespace of their node",
+	hostPIDRule:                      "Pods must not use the process namespace of their node",
+	hostIPCRule:                      "Pods must not use the IPC namespace of their node",
+	hostPathRule:                     "Pods must not mount hostPath volumes",
+	writableRootFilesystemRule:       "Containers should have a read-only root filesystem",
+	seccompProfileRule:               "Containers must run with a seccomp profile other than Unconfined",
+	appArmorProfileRule:              "Containers must not run with the unconfined AppArmor profile",
+	automountServiceAccountRule:      "Pods should not mount a service account token they do not need",
+	bannedImageRule:                  "Images must not be banned",
+	podSecurityCategory + "-enforce": "Pods must satisfy the Pod Security level enforced by their namespace",
+	podSecurityCategory + "-warn":    "Pods should satisfy the Pod Security level their namespace warns about",
+	podSecurityCategory + "-audit":   "Pods should satisfy the Pod Security level their namespace audits",
+}
+
+// ruleDescription returns the short description of the rule of a finding.
+func ruleDescription(finding Finding) string {
+	if description := ruleDescriptions[finding.RuleID]; description != "" {
+		return description
+	}
+	for _, rule := range utils.GetRules() {
+		if rule.ID == finding.RuleID {
+			return rule.Message
+		}
+	}
+	if finding.Category == imageCategory {
+		return fmt.Sprintf("Images must not be affected by %s", finding.RuleID)
+	}
+	return finding.RuleID
+}
+
+// findingLocation locates a finding in the manifest file of its workload when it is known, or at the
+// synthetic k8s://namespace/kind/name URI of the workload otherwise.
+func findingLocation(finding Finding) utils.SARIFLocation {
+	uri := finding.Source
+	if uri == "" {
+		uri = utils.ResourceURI(finding.Namespace, finding.Kind, finding.Name)
+	}
+	qualifiedName := fmt.Sprintf("%s/%s/%s", finding.Namespace, finding.Kind, finding.Name)
+	name := finding.Name
+	if finding.Container != "" {
+		qualifiedName += "/" + finding.Container
+		name = finding.Container
+	}
+	return utils.SARIFLocation{
+		PhysicalLocation: utils.SARIFPhysicalLocation{ArtifactLocation: utils.SARIFArtifactLocation{URI: uri}},
+		LogicalLocations: []utils.SARIFLogicalLocation{{Name: name, FullyQualifiedName: qualifiedName, Kind: "resource"}},
+	}
+}
+
+// findingFingerprint identifies a finding by its rule and resource, so that dashboards track it across runs.
+func findingFingerprint(finding Finding) string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{finding.RuleID, finding.Namespace, finding.Kind, finding.Name, finding.Container}, "/")))
+	return hex.EncodeToString(hash[:16])
+}
+
+// BuildSARIF returns the findings as a SARIF 2.1.0 log: one rule per rule ID, at the level of its most
+// severe finding, and one result per finding.
+func BuildSARIF(findings []Finding) *utils.SARIFLog {
+	byRule := map[string][]Finding{}
+	for _, finding := range findings {
+		byRule[finding.RuleID] = append(byRule[finding.RuleID], finding)
+	}
+
+	log := utils.NewSARIFLog()
+	run := &log.Runs[0]
+	ruleIndexes := map[string]int{}
+	for _, ruleID := range sortedKeys(byRule) {
+		ruleFindings := byRule[ruleID]
+		ruleIndexes[ruleID] = len(run.Tool.Driver.Rules)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, utils.SARIFRule{
+			ID:                   ruleID,
+			ShortDescription:     utils.SARIFMessage{Text: ruleDescription(ruleFindings[0])},
+			DefaultConfiguration: utils.SARIFConfiguration{Level: sarifLevels[highestSeverity(ruleFindings).(string)]},
+			Properties:           map[string]interface{}{"tags": []string{ruleFindings[0].Category}},
+		})
+	}
+
+	for _, finding := range findings {
+		run.Results = append(run.Results, utils.SARIFResult{
+			RuleID:              finding.RuleID,
+			RuleIndex:           ruleIndexes[finding.RuleID],
+			Level:               sarifLevels[finding.Severity],
+			Message:             utils.SARIFMessage{Text: finding.Message},
+			Locations:           []utils.SARIFLocation{findingLocation(finding)},
+			PartialFingerprints: map[string]string{sarifFingerprint: findingFingerprint(finding)},
+		})
+	}
+	utils.Info("Built SARIF log", zap.Int("rules", len(run.Tool.Driver.Rules)), zap.Int("results", len(run.Results)))
+	return log
+}
@@ -0,0 +1,302 @@
+// handlers/security.go
+
+package handlers
+
+import (
+	"fmt"
+	"k8s-reporter/utils"
+	"strings"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+)
+
+// securityCategory is the category of the findings about the security context of workloads.
+const securityCategory = "security"
+
+// Rule IDs of the security context checks.
+const (
+	privilegedRule              = "privileged-container"
+	runAsRootRule               = "run-as-root"
+	privilegeEscalationRule     = "allow-privilege-escalation"
+	addedCapabilitiesRule       = "added-capabilities"
+	hostNetworkRule             = "host-network"
+	hostPIDRule                 = "host-pid"
+	hostIPCRule                 = "host-ipc"
+	hostPathRule                = "host-path-volume"
+	writableRootFilesystemRule  = "writable-root-filesystem"
+	seccompProfileRule          = "seccomp-profile"
+	appArmorProfileRule         = "apparmor-profile"
+	automountServiceAccountRule = "automount-service-account-token"
+)
+
+// Security profiles and capabilities the checks look at.
+const (
+	seccompPodAnnotation        = "seccomp.security.alpha.kubernetes.io/pod"
+	seccompContainerAnnotation  = "container.seccomp.security.alpha.kubernetes.io/"
+	appArmorContainerAnnotation = "container.apparmor.security.beta.kubernetes.io/"
+	unconfinedProfile           = "unconfined"
+	netBindServiceCapability    = "NET_BIND_SERVICE"
+	allCapabilities             = "ALL"
+)
+
+// dangerousCapabilities are the capabilities that give a container control over its node.
+var dangerousCapabilities = map[string]bool{
+	allCapabilities:   true,
+	"SYS_ADMIN":       true,
+	"NET_ADMIN":       true,
+	"SYS_PTRACE":      true,
+	"SYS_MODULE":      true,
+	"DAC_READ_SEARCH": true,
+}
+
+var SecurityHeaders = []string{
+	"Kind",
+	"Namespace",
+	"Name",
+	"Privileged Containers",
+	"Not Running As Non-Root",
+	"Allow Privilege Escalation",
+	"Added Capabilities",
+	"Host Network",
+	"Host PID",
+	"Host IPC",
+	"HostPath Volumes",
+	"Writable Root Filesystem",
+	"Seccomp Profile",
+	"AppArmor Profile",
+	"Automount Service Account Token",
+	"Errors",
+	"Warnings",
+	"Infos",
+	"Highest Severity",
+}
+
+// securityAudit is the result of the security context checks of a workload: its findings, and what
+// each rule found (e.g. the containers it applies to), by rule ID.
+type securityAudit struct {
+	findings []Finding
+	details  map[string][]string
+}
+
+func (a *securityAudit) add(workload Workload, container string, ruleID string, severity string, detail string, message string) {
+	a.findings = append(a.findings, newFinding(workload, container, securityCategory, ruleID, severity, message))
+	a.details[ruleID] = append(a.details[ruleID], detail)
+}
+
+// auditSecurity checks the security context of the pod template of a workload and of every
+// container it runs, including init containers and sidecars.
+func auditSecurity(workload Workload) securityAudit {
+	audit := securityAudit{details: map[string][]string{}}
+	podSpec := workload.PodSpec
+	podContext := podSpec.SecurityContext
+	if podContext == nil {
+		podContext = &v1.PodSecurityContext{}
+	}
+
+	if podSpec.HostNetwork {
+		audit.add(workload, "", hostNetworkRule, utils.SeverityError, "", "Pod uses the network namespace of its node (hostNetwork)")
+	}
+	if podSpec.HostPID {
+		audit.add(workload, "", hostPIDRule, utils.SeverityError, "", "Pod uses the process namespace of its node (hostPID)")
+	}
+	if podSpec.HostIPC {
+		audit.add(workload, "", hostIPCRule, utils.SeverityError, "", "Pod uses the IPC namespace of its node (hostIPC)")
+	}
+	for _, volume := range podSpec.Volumes {
+		if volume.HostPath != nil {
+			audit.add(workload, "", hostPathRule, utils.SeverityError, fmt.Sprintf("%s (%s)", volume.Name, volume.HostPath.Path),
+				fmt.Sprintf("Volume %s mounts the path %s of its node (hostPath)", volume.Name, volume.HostPath.Path))
+		}
+	}
+	if podSpec.AutomountServiceAccountToken == nil || *podSpec.AutomountServiceAccountToken {
+		audit.add(workload, "", automountServiceAccountRule, utils.SeverityInfo, "",
+			"Pod mounts a service account token (automountServiceAccountToken is not false), unless its service account disables it")
+	}
+
+	containers := append(append([]v1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	for _, container := range containers {
+		auditContainer(&audit, workload, podContext, container)
+	}
+	return audit
+}
+
+// auditContainer checks the effective security context of a container: its own, falling back to the
+// one of its pod.
+func auditContainer(audit *securityAudit, workload Workload, podContext *v1.PodSecurityContext, container v1.Container) {
+	name := container.Name
+	context := container.SecurityContext
+	if context == nil {
+		context = &v1.SecurityContext{}
+	}
+
+	privileged := context.Privileged != nil && *context.Privileged
+	if privileged {
+		audit.add(workload, name, privilegedRule, utils.SeverityError, name, fmt.Sprintf("Container %s is privileged", name))
+	}
+
+	runAsNonRoot := podContext.RunAsNonRoot
+	if context.RunAsNonRoot != nil {
+		runAsNonRoot = context.RunAsNonRoot
+	}
+	runAsUser := podContext.RunAsUser
+	if context.RunAsUser != nil {
+		runAsUser = context.RunAsUser
+	}
+	switch {
+	case runAsUser != nil && *runAsUser == 0:
+		audit.add(workload, name, runAsRootRule, utils.SeverityError, name, fmt.Sprintf("Container %s runs as root (runAsUser: 0)", name))
+	case (runAsNonRoot == nil || !*runAsNonRoot) && runAsUser == nil:
+		audit.add(workload, name, runAsRootRule, utils.SeverityWarning, name, fmt.Sprintf("Container %s may run as root: runAsNonRoot is not set", name))
+	}
+
+	if privileged || context.AllowPrivilegeEscalation == nil || *context.AllowPrivilegeEscalation {
+		audit.add(workload, name, privilegeEscalationRule, utils.SeverityWarning, name, fmt.Sprintf("Container %s allows privilege escalation: allowPrivilegeEscalation is not false", name))
+	}
+
+	if context.Capabilities != nil {
+		var added []string
+		severity := utils.SeverityWarning
+		for _, capability := range context.Capabilities.Add {
+			capabilityName := strings.TrimPrefix(strings.ToUpper(string(capability)), "CAP_")
+			if capabilityName == netBindServiceCapability {
+				continue
+			}
+			added = append(added, capabilityName)
+			if dangerousCapabilities[capabilityName] {
+				severity = utils.SeverityError
+			}
+		}
+		if len(added) > 0 {
+			audit.add(workload, name, addedCapabilitiesRule, severity, fmt.Sprintf("%s: %s", name, strings.Join(added, " ")),
+				fmt.Sprintf("Container %s adds the capabilities %s", name, strings.Join(added, ", ")))
+		}
+	}
+
+	if context.ReadOnlyRootFilesystem == nil || !*context.ReadOnlyRootFilesystem {
+		audit.add(workload, name, writableRootFilesystemRule, utils.SeverityWarning, name, fmt.Sprintf("Container %s has a writable root filesystem: readOnlyRootFilesystem is not true", name))
+	}
+
+	switch seccomp := seccompProfileOf(workload.PodAnnotations, podContext, context, name); seccomp {
+	case "":
+		audit.add(workload, name, seccompProfileRule, utils.SeverityWarning, fmt.Sprintf("%s: none", name), fmt.Sprintf("Container %s has no seccomp profile", name))
+	case unconfinedProfile:
+		audit.add(workload, name, seccompProfileRule, utils.SeverityError, fmt.Sprintf("%s: %s", name, seccomp), fmt.Sprintf("Container %s runs with the Unconfined seccomp profile", name))
+	}
+
+	if profile := workload.PodAnnotations[appArmorContainerAnnotation+name]; strings.ToLower(profile) == unconfinedProfile {
+		audit.add(workload, name, appArmorProfileRule, utils.SeverityError, fmt.Sprintf("%s: %s", name, profile), fmt.Sprintf("Container %s runs with the unconfined AppArmor profile", name))
+	}
+}
+
+// seccompProfileOf returns the effective seccomp profile of a container, lowercased: "runtimedefault",
+// "localhost", "unconfined", or empty when none is set. The deprecated annotations are honoured.
+func seccompProfileOf(annotations map[string]string, podContext *v1.PodSecurityContext, context *v1.SecurityContext, name string) string {
+	switch {
+	case context.SeccompProfile != nil:
+		return strings.ToLower(string(context.SeccompProfile.Type))
+	case annotations[seccompContainerAnnotation+name] != "":
+		return seccompAnnotationProfile(annotations[seccompContainerAnnotation+name])
+	case podContext.SeccompProfile != nil:
+		return strings.ToLower(string(podContext.SeccompProfile.Type))
+	case annotations[seccompPodAnnotation] != "":
+		return seccompAnnotationProfile(annotations[seccompPodAnnotation])
+	default:
+		return ""
+	}
+}
+
+// seccompAnnotationProfile maps the value of a deprecated seccomp annotation to a profile type.
+func seccompAnnotationProfile(value string) string {
+	switch {
+	case value == "runtime/default" || value == "docker/default":
+		return "runtimedefault"
+	case strings.HasPrefix(value, "localhost/"):
+		return "localhost"
+	default:
+		return unconfinedProfile
+	}
+}
+
+// severityCounts counts findings per severity.
+func severityCounts(findings []Finding) map[string]int {
+	counts := map[string]int{}
+	for _, finding := range findings {
+		counts[finding.Severity]++
+	}
+	return counts
+}
+
+// highestSeverity returns the most severe severity of the given findings, or nil without findings.
+func highestSeverity(findings []Finding) interface{} {
+	counts := severityCounts(findings)
+	for _, severity := range []string{utils.SeverityError, utils.SeverityWarning, utils.SeverityInfo} {
+		if counts[severity] > 0 {
+			return severity
+		}
+	}
+	return nil
+}
+
+// securityFindingsCell summarizes the security findings of a workload for the "Security Findings"
+// column, e.g. "2 error, 3 warning, 1 info".
+func securityFindingsCell(workload Workload) string {
+	counts := severityCounts(auditSecurity(workload).findings)
+	var cells []string
+	for _, severity := range []string{utils.SeverityError, utils.SeverityWarning, utils.SeverityInfo} {
+		if counts[severity] > 0 {
+			cells = append(cells, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+	return strings.Join(cells, ", ")
+}
+
+// securityRecord builds the report row of the security audit of a single workload.
+func securityRecord(workload Workload, audit securityAudit) []interface{} {
+	list := func(ruleID string) string {
+		return strings.Join(audit.details[ruleID], ", ")
+	}
+	found := func(ruleID string) bool {
+		return len(audit.details[ruleID]) > 0
+	}
+	counts := severityCounts(audit.findings)
+	return []interface{}{
+		workload.Kind,
+		workload.Namespace,
+		workload.Name,
+		list(privilegedRule),
+		list(runAsRootRule),
+		list(privilegeEscalationRule),
+		list(addedCapabilitiesRule),
+		found(hostNetworkRule),
+		found(hostPIDRule),
+		found(hostIPCRule),
+		list(hostPathRule),
+		list(writableRootFilesystemRule),
+		list(seccompProfileRule),
+		list(appArmorProfileRule),
+		found(automountServiceAccountRule),
+		counts[utils.SeverityError],
+		counts[utils.SeverityWarning],
+		counts[utils.SeverityInfo],
+		highestSeverity(audit.findings),
+	}
+}
+
+// WriteSecurity writes the security context audit of the given workloads, one row per workload listing
+// what every check found, with the number of findings per severity.
+func WriteSecurity(writer utils.ReportWriter, section string, workloads []Workload) error {
+	utils.Info("Writing Security audit to report", zap.String("section", section))
+	if err := writer.AddSection(section, SecurityHeaders); err != nil {
+		utils.Error("Failed to add Security section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+	for _, workload := range workloads {
+		if err := writer.WriteRow(section, securityRecord(workload, auditSecurity(workload))); err != nil {
+			utils.Error("Failed to write report row for security audit", zap.String("kind", workload.Kind), zap.String("name", workload.Name), zap.Error(err))
+			return err
+		}
+	}
+	utils.Info("Successfully written Security audit to report", zap.String("section", section))
+	return nil
+}
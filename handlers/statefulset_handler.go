@@ -5,9 +5,8 @@ package handlers
 import (
 	"context"
 	"k8s-reporter/utils"
-	"strconv"
+	"strings"
 
-	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,6 +17,7 @@ import (
 // for Kubernetes Statefulsets.
 type StatefulsetHandler struct {
 	Statefulsets []appsv1.StatefulSet
+	clientset    kubernetes.Interface
 }
 
 var StatefulsetHeaders = []string{
@@ -29,20 +29,34 @@ var StatefulsetHeaders = []string{
 	"Up-to-date",
 	"Available",
 	"Node Selector",
-	"CPU Requests",
-	"Memory Requests",
-	"CPU Limits",
-	"Memory Limits",
-	"CPU Diff",
-	"Memory Diff",
+	"CPU Requests (m)",
+	"Memory Requests (MiB)",
+	"CPU Limits (m)",
+	"Memory Limits (MiB)",
+	"CPU Diff (m)",
+	"Memory Diff (MiB)",
 	"Memory diff > 2 x Request",
 	"Image Versions",
 	"QoS Class",
 	"Owner",
+	"LimitRange Defaults Applied",
+	"LimitRange Violations",
+	"Init CPU Requests (m)",
+	"Init Memory Requests (MiB)",
+	"Sidecar CPU Requests (m)",
+	"Sidecar Memory Requests (MiB)",
+	"Overhead CPU (m)",
+	"Overhead Memory (MiB)",
+	"Total CPU Requests (m)",
+	"Total Memory Requests (MiB)",
+	"Total CPU Limits (m)",
+	"Total Memory Limits (MiB)",
+	"Image Findings",
+	"Security Findings",
 }
 
 // FetchResources fetches all Statefulsets across all namespaces and stores them.
-func (d *StatefulsetHandler) FetchResources(clientset *kubernetes.Clientset) error {
+func (d *StatefulsetHandler) FetchResources(clientset kubernetes.Interface) error {
 	utils.Info("Fetching Statefulsets from Kubernetes cluster")
 	statefulsets, err := clientset.AppsV1().StatefulSets("").List(context.Background(), metav1.ListOptions{})
 	if err != nil {
@@ -50,65 +64,100 @@ func (d *StatefulsetHandler) FetchResources(clientset *kubernetes.Clientset) err
 		return err
 	}
 	d.Statefulsets = statefulsets.Items
+	d.clientset = clientset
 	utils.Info("Fetched Statefulsets", zap.Int("count", len(d.Statefulsets)))
 	return nil
 }
 
-// WriteExcel writes the information of the fetched Statefulsets to an Excel sheet.
-func (d *StatefulsetHandler) WriteExcel(clientset *kubernetes.Clientset, f *excelize.File, sheetName string) error {
-	utils.Info("Writing Statefulsets data to Excel sheet", zap.String("sheetName", sheetName))
-	// Starting from the second row, since the first row is for headers
-	rowIndex := 2
-	for _, statefulset := range d.Statefulsets {
-		name := statefulset.Name
-		namespace := statefulset.Namespace
-		desiredReplicas := statefulset.Spec.Replicas
-		currentReplicas := statefulset.Status.Replicas
-		availableReplicas := statefulset.Status.AvailableReplicas
-		readyReplicas := statefulset.Status.ReadyReplicas
-		uptodateReplicas := statefulset.Status.UpdatedReplicas
-		nodeSelector := statefulset.Spec.Template.Spec.NodeSelector
-		cpuRequests, memoryRequests, cpuLimits, memoryLimits, cpuDiff, memoryDiff, memoryReadiness, qosClass := utils.ExtractResources(clientset, statefulset.Spec.Template.Spec, namespace)
-		imageVersions := utils.ExtractImageVersions(statefulset.Spec.Template.Spec)
-		// qosClass := utils.DetermineQoSClass(statefulset.Spec.Template.Spec)
-		desired := "unknown"
-		if desiredReplicas != nil {
-			desired = strconv.Itoa(int(*desiredReplicas))
-		}
+// workload builds the common view of a single StatefulSet, running as many pods as its desired replicas.
+func (d *StatefulsetHandler) workload(statefulset appsv1.StatefulSet) Workload {
+	replicas := int32(1)
+	if statefulset.Spec.Replicas != nil {
+		replicas = *statefulset.Spec.Replicas
+	}
+	return Workload{
+		Kind:            "StatefulSet",
+		Name:            statefulset.Name,
+		Namespace:       statefulset.Namespace,
+		Object:          &statefulset,
+		Replicas:        replicas,
+		Resources:       utils.ExtractResources(d.clientset, statefulset.Spec.Template.Spec, statefulset.Namespace),
+		PodSpec:         statefulset.Spec.Template.Spec,
+		PodAnnotations:  statefulset.Spec.Template.Annotations,
+		NamespaceLabels: utils.GetNamespaceLabels(d.clientset, statefulset.Namespace),
+		Usage:           utils.GetWorkloadUsage(d.clientset, "StatefulSet", statefulset.Namespace, statefulset.Name),
+	}
+}
 
-		record := []interface{}{
-			name,
-			namespace,
-			desired,
-			strconv.Itoa(int(currentReplicas)),
-			strconv.Itoa(int(readyReplicas)),
-			strconv.Itoa(int(uptodateReplicas)),
-			strconv.Itoa(int(availableReplicas)),
-			utils.FormatNodeSelector(nodeSelector),
-			cpuRequests,
-			memoryRequests,
-			cpuLimits,
-			memoryLimits,
-			cpuDiff,
-			memoryDiff,
-			memoryReadiness,
-			imageVersions,
-			qosClass,
-		}
+// record builds the report row of a single Statefulset.
+func (d *StatefulsetHandler) record(statefulset appsv1.StatefulSet, workload Workload) []interface{} {
+	name := statefulset.Name
+	namespace := statefulset.Namespace
+	desiredReplicas := statefulset.Spec.Replicas
+	currentReplicas := statefulset.Status.Replicas
+	availableReplicas := statefulset.Status.AvailableReplicas
+	readyReplicas := statefulset.Status.ReadyReplicas
+	uptodateReplicas := statefulset.Status.UpdatedReplicas
+	nodeSelector := statefulset.Spec.Template.Spec.NodeSelector
+	resources := workload.Resources
+	imageVersions := utils.ExtractImageVersions(statefulset.Spec.Template.Spec)
+	// Left empty when unknown
+	var desired interface{}
+	if desiredReplicas != nil {
+		desired = *desiredReplicas
+	}
 
-		for i, value := range record {
-			cell, err := excelize.CoordinatesToCellName(i+1, rowIndex)
-			if err != nil {
-				utils.Error("Failed to convert coordinates to cell name for Statefulset", zap.String("statefulsetName", name), zap.Error(err))
-				return err
-			}
-			if err := f.SetCellValue(sheetName, cell, value); err != nil {
-				utils.Error("Failed to set cell value for Statefulset", zap.String("statefulsetName", name), zap.Error(err))
-				return err
-			}
+	return []interface{}{
+		name,
+		namespace,
+		desired,
+		currentReplicas,
+		readyReplicas,
+		uptodateReplicas,
+		availableReplicas,
+		utils.FormatNodeSelector(nodeSelector),
+		utils.CPUMillicores(resources.CPURequests),
+		utils.MemoryMiB(resources.MemoryRequests),
+		utils.CPUMillicores(resources.CPULimits),
+		utils.MemoryMiB(resources.MemoryLimits),
+		utils.CPUMillicores(resources.CPUDiff()),
+		utils.MemoryMiB(resources.MemoryDiff()),
+		resources.MemoryReadiness(),
+		imageVersions,
+		resources.QoSClass,
+		utils.ResolveOwner(d.clientset, statefulset.ObjectMeta),
+		resources.DefaultsApplied,
+		strings.Join(resources.LimitRangeViolations, "; "),
+		utils.CPUMillicores(resources.InitCPURequests),
+		utils.MemoryMiB(resources.InitMemoryRequests),
+		utils.CPUMillicores(resources.SidecarCPURequests),
+		utils.MemoryMiB(resources.SidecarMemoryRequests),
+		utils.CPUMillicores(resources.OverheadCPU),
+		utils.MemoryMiB(resources.OverheadMemory),
+		utils.CPUMillicores(workload.TotalCPURequests()),
+		utils.MemoryMiB(workload.TotalMemoryRequests()),
+		utils.CPUMillicores(workload.TotalCPULimits()),
+		utils.MemoryMiB(workload.TotalMemoryLimits()),
+		imageFindingsCell(workload),
+		securityFindingsCell(workload),
+	}
+}
+
+// WriteReport writes the information of the fetched Statefulsets to a report section.
+func (d *StatefulsetHandler) WriteReport(writer utils.ReportWriter, section string) error {
+	utils.Info("Writing Statefulsets data to report", zap.String("section", section))
+	if err := writer.AddSection(section, StatefulsetHeaders); err != nil {
+		utils.Error("Failed to add Statefulsets section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+	for _, statefulset := range d.Statefulsets {
+		workload := d.workload(statefulset)
+		RecordWorkload(workload)
+		if err := writer.WriteRow(section, d.record(statefulset, workload)); err != nil {
+			utils.Error("Failed to write report row for Statefulset", zap.String("statefulsetName", statefulset.Name), zap.Error(err))
+			return err
 		}
-		rowIndex++
 	}
-	utils.Info("Successfully written Statefulset data to Excel sheet", zap.String("sheetName", sheetName))
+	utils.Info("Successfully written Statefulset data to report", zap.String("section", section))
 	return nil
 }
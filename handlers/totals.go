@@ -0,0 +1,108 @@
+// handlers/totals.go
+
+package handlers
+
+import (
+	"sort"
+
+	"k8s-reporter/utils"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// allValue marks the subtotal rows of the Totals section.
+const allValue = "All"
+
+var TotalsHeaders = []string{
+	"Namespace",
+	"Kind",
+	"Workloads",
+	"Total CPU Requests (m)",
+	"Total Memory Requests (MiB)",
+	"Total CPU Limits (m)",
+	"Total Memory Limits (MiB)",
+}
+
+// footprint accumulates the total requests and limits of a group of workloads.
+type footprint struct {
+	workloads      int
+	cpuRequests    resource.Quantity
+	memoryRequests resource.Quantity
+	cpuLimits      resource.Quantity
+	memoryLimits   resource.Quantity
+}
+
+func (f *footprint) add(workload Workload) {
+	f.workloads++
+	f.cpuRequests.Add(workload.TotalCPURequests())
+	f.memoryRequests.Add(workload.TotalMemoryRequests())
+	f.cpuLimits.Add(workload.TotalCPULimits())
+	f.memoryLimits.Add(workload.TotalMemoryLimits())
+}
+
+func (f *footprint) record(namespace string, kind string) []interface{} {
+	return []interface{}{
+		namespace,
+		kind,
+		f.workloads,
+		utils.CPUMillicores(f.cpuRequests),
+		utils.MemoryMiB(f.memoryRequests),
+		utils.CPUMillicores(f.cpuLimits),
+		utils.MemoryMiB(f.memoryLimits),
+	}
+}
+
+// WriteTotals writes the total footprint (pod resources multiplied by replicas) of the given
+// workloads per namespace and kind, followed by a subtotal per namespace and a cluster-wide total.
+func WriteTotals(writer utils.ReportWriter, section string, workloads []Workload) error {
+	utils.Info("Writing Totals to report", zap.String("section", section))
+	if err := writer.AddSection(section, TotalsHeaders); err != nil {
+		utils.Error("Failed to add Totals section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+
+	byKind := map[string]map[string]*footprint{}
+	byNamespace := map[string]*footprint{}
+	cluster := &footprint{}
+	for _, workload := range workloads {
+		if byKind[workload.Namespace] == nil {
+			byKind[workload.Namespace] = map[string]*footprint{}
+			byNamespace[workload.Namespace] = &footprint{}
+		}
+		if byKind[workload.Namespace][workload.Kind] == nil {
+			byKind[workload.Namespace][workload.Kind] = &footprint{}
+		}
+		byKind[workload.Namespace][workload.Kind].add(workload)
+		byNamespace[workload.Namespace].add(workload)
+		cluster.add(workload)
+	}
+
+	var records [][]interface{}
+	for _, namespace := range sortedKeys(byNamespace) {
+		for _, kind := range sortedKeys(byKind[namespace]) {
+			records = append(records, byKind[namespace][kind].record(namespace, kind))
+		}
+		records = append(records, byNamespace[namespace].record(namespace, allValue))
+	}
+	records = append(records, cluster.record(allValue, allValue))
+
+	for _, record := range records {
+		if err := writer.WriteRow(section, record); err != nil {
+			utils.Error("Failed to write report row for Totals", zap.Error(err))
+			return err
+		}
+	}
+	utils.Info("Successfully written Totals to report", zap.String("section", section))
+	return nil
+}
+
+// sortedKeys returns the keys of a map in alphabetical order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
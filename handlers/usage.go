@@ -0,0 +1,81 @@
+// handlers/usage.go
+
+package handlers
+
+import (
+	"k8s-reporter/utils"
+
+	"go.uber.org/zap"
+)
+
+var UsageHeaders = []string{
+	"Kind",
+	"Namespace",
+	"Name",
+	"Pods",
+	"CPU Usage (m)",
+	"Memory Usage (MiB)",
+	"CPU Requests (m)",
+	"Memory Requests (MiB)",
+	"CPU Limits (m)",
+	"Memory Limits (MiB)",
+	"CPU Usage / Requests (%)",
+	"Memory Usage / Requests (%)",
+	"CPU Usage / Limits (%)",
+	"Memory Usage / Limits (%)",
+}
+
+// usageRecord builds the report row comparing the current usage of a workload to the requests and
+// limits of the pods it was measured over. The usage cells are left empty when no pod was measured.
+func usageRecord(workload Workload) []interface{} {
+	usage := workload.Usage
+	if usage == nil {
+		usage = &utils.ResourceUsage{}
+	}
+	pods := int32(usage.Pods)
+	resources := workload.Resources
+	cpuRequests := utils.MultiplyQuantity(resources.CPURequests, pods)
+	memoryRequests := utils.MultiplyQuantity(resources.MemoryRequests, pods)
+	cpuLimits := utils.MultiplyQuantity(resources.CPULimits, pods)
+	memoryLimits := utils.MultiplyQuantity(resources.MemoryLimits, pods)
+
+	record := []interface{}{
+		workload.Kind,
+		workload.Namespace,
+		workload.Name,
+		usage.Pods,
+	}
+	if workload.Usage == nil {
+		return append(record, make([]interface{}, len(UsageHeaders)-len(record))...)
+	}
+	return append(record,
+		utils.CPUMillicores(usage.CPU),
+		utils.MemoryMiB(usage.Memory),
+		utils.CPUMillicores(cpuRequests),
+		utils.MemoryMiB(memoryRequests),
+		utils.CPUMillicores(cpuLimits),
+		utils.MemoryMiB(memoryLimits),
+		percentOf(usage.CPU, cpuRequests),
+		percentOf(usage.Memory, memoryRequests),
+		percentOf(usage.CPU, cpuLimits),
+		percentOf(usage.Memory, memoryLimits),
+	)
+}
+
+// WriteUsage writes the current usage of the given workloads, summed over their running pods, next to
+// the requests and limits of those pods, so that over- and under-provisioned workloads stand out.
+func WriteUsage(writer utils.ReportWriter, section string, workloads []Workload) error {
+	utils.Info("Writing Usage to report", zap.String("section", section))
+	if err := writer.AddSection(section, UsageHeaders); err != nil {
+		utils.Error("Failed to add Usage section to report", zap.String("section", section), zap.Error(err))
+		return err
+	}
+	for _, workload := range workloads {
+		if err := writer.WriteRow(section, usageRecord(workload)); err != nil {
+			utils.Error("Failed to write report row for workload usage", zap.String("kind", workload.Kind), zap.String("name", workload.Name), zap.Error(err))
+			return err
+		}
+	}
+	utils.Info("Successfully written Usage to report", zap.String("section", section))
+	return nil
+}
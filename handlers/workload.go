@@ -0,0 +1,111 @@
+// handlers/workload.go
+
+package handlers
+
+import (
+	"sync"
+
+	"k8s-reporter/utils"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Workload is the common view of a resource running pods from a pod template. Handlers record
+// the workloads they report so that the sections aggregating every resource kind can use them.
+type Workload struct {
+	Kind      string
+	Name      string
+	Namespace string
+	// Object is the Kubernetes resource of the workload, e.g. an *appsv1.Deployment.
+	Object runtime.Object
+	// Replicas is the number of pods the workload runs at once.
+	Replicas  int32
+	Resources utils.PodResources
+	// PodSpec is the pod template of the workload.
+	PodSpec v1.PodSpec
+	// PodAnnotations are the annotations of the pod template of the workload.
+	PodAnnotations map[string]string
+	// NamespaceLabels are the labels of the namespace of the workload.
+	NamespaceLabels map[string]string
+	// Usage is the current usage of the running pods of the workload, nil unless --with-usage is set.
+	Usage *utils.ResourceUsage
+}
+
+// TotalCPURequests returns the CPU requests of all the pods of the workload.
+func (w Workload) TotalCPURequests() resource.Quantity {
+	return utils.MultiplyQuantity(w.Resources.CPURequests, w.Replicas)
+}
+
+// TotalMemoryRequests returns the memory requests of all the pods of the workload.
+func (w Workload) TotalMemoryRequests() resource.Quantity {
+	return utils.MultiplyQuantity(w.Resources.MemoryRequests, w.Replicas)
+}
+
+// TotalCPULimits returns the CPU limits of all the pods of the workload.
+func (w Workload) TotalCPULimits() resource.Quantity {
+	return utils.MultiplyQuantity(w.Resources.CPULimits, w.Replicas)
+}
+
+// TotalMemoryLimits returns the memory limits of all the pods of the workload.
+func (w Workload) TotalMemoryLimits() resource.Quantity {
+	return utils.MultiplyQuantity(w.Resources.MemoryLimits, w.Replicas)
+}
+
+var (
+	workloads      []Workload
+	workloadsMutex sync.Mutex
+)
+
+// RecordWorkload adds a reported workload to the inventory of this run.
+func RecordWorkload(workload Workload) {
+	workloadsMutex.Lock()
+	defer workloadsMutex.Unlock()
+	workloads = append(workloads, workload)
+}
+
+// Workloads returns every workload reported so far, in the order they were reported.
+func Workloads() []Workload {
+	workloadsMutex.Lock()
+	defer workloadsMutex.Unlock()
+	return append([]Workload{}, workloads...)
+}
+
+// FetchWorkloads fetches the Deployments, DaemonSets, StatefulSets, Jobs and CronJobs across all
+// namespaces and returns their workloads, without recording them. The Jobs of a CronJob are left out,
+// their CronJob stands for them.
+func FetchWorkloads(clientset kubernetes.Interface) ([]Workload, error) {
+	deployments := &DeploymentHandler{}
+	daemonSets := &DaemonSetHandler{}
+	statefulsets := &StatefulsetHandler{}
+	jobs := &JobHandler{}
+	cronJobs := &CronJobHandler{}
+	for _, handler := range []ResourceHandler{deployments, daemonSets, statefulsets, jobs, cronJobs} {
+		if err := handler.FetchResources(clientset); err != nil {
+			return nil, err
+		}
+	}
+
+	var result []Workload
+	for _, deployment := range deployments.Deployments {
+		result = append(result, deployments.workload(deployment))
+	}
+	for _, ds := range daemonSets.DaemonSets {
+		result = append(result, daemonSets.workload(ds))
+	}
+	for _, statefulset := range statefulsets.Statefulsets {
+		result = append(result, statefulsets.workload(statefulset))
+	}
+	for _, job := range jobs.Jobs {
+		if spawnedByCronJob(&job) {
+			continue
+		}
+		result = append(result, jobs.workload(job))
+	}
+	for _, cronJob := range cronJobs.CronJobs {
+		result = append(result, cronJobs.workload(cronJob))
+	}
+	return result, nil
+}
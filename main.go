@@ -4,6 +4,8 @@ Copyright © 2024 Evyatar Shtern
 package main
 
 import (
+	"os"
+
 	"k8s-reporter/cmd"
 	"k8s-reporter/utils"
 
@@ -11,18 +13,22 @@ import (
 )
 
 func main() {
-	// Ensure the finalization function runs when the main function exits
-	defer finalize()
+	exitCode := cmd.Execute()
 
-	cmd.Execute()
+	// The report is saved even when findings fail the run, so that they can be looked at
+	if err := finalize(); err != nil {
+		exitCode = cmd.ExitError
+	}
+	os.Exit(exitCode)
 }
 
-// finalize is the finalization function that saves the Excel file.
-func finalize() {
-	utils.Info("Finalizing and saving the Excel report")
-	excelManager := utils.GetExcelFileManager()
-	if err := excelManager.SaveExcelFile("k8s_report.xlsx"); err != nil {
-		utils.Fatal("Failed to save the Excel report: ", zap.Error(err))
+// finalize is the finalization function that saves the report.
+func finalize() error {
+	utils.Info("Finalizing and saving the report")
+	if err := utils.CloseReportWriter(); err != nil {
+		utils.Error("Failed to save the report", zap.Error(err))
+		return err
 	}
-	utils.Info("Excel report saved successfully.")
+	utils.Info("Report saved successfully.")
+	return nil
 }
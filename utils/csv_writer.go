@@ -0,0 +1,109 @@
+// utils/csv_writer.go
+
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// CreateCSVFile creates (or truncates) a CSV file and writes the given headers as its first row.
+// The caller is responsible for flushing the writer and closing the file.
+func CreateCSVFile(filePath string, headers []string) (*os.File, *csv.Writer, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		Error("Failed to create CSV file", zap.String("filePath", filePath), zap.Error(err))
+		return nil, nil, err
+	}
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(headers); err != nil {
+		Error("Failed to write CSV headers", zap.String("filePath", filePath), zap.Error(err))
+		file.Close()
+		return nil, nil, err
+	}
+	return file, writer, nil
+}
+
+// FormatCSVRecord converts a report record to the string values expected by a CSV writer.
+func FormatCSVRecord(record []interface{}) []string {
+	values := make([]string, len(record))
+	for i, value := range record {
+		if value == nil {
+			continue
+		}
+		values[i] = fmt.Sprint(value)
+	}
+	return values
+}
+
+// csvSection is a single CSV file of a CSV report.
+type csvSection struct {
+	file   *os.File
+	writer *csv.Writer
+}
+
+// csvReportWriter writes every section of a report to its own CSV file.
+type csvReportWriter struct {
+	baseName string
+	sections map[string]*csvSection
+}
+
+func newCSVReportWriter(baseName string) *csvReportWriter {
+	return &csvReportWriter{baseName: baseName, sections: map[string]*csvSection{}}
+}
+
+// csvFilePath returns the file a section is written to, e.g. k8s_report_deployments.csv.
+func (w *csvReportWriter) csvFilePath(section string) string {
+	return fmt.Sprintf("%s_%s.csv", w.baseName, strings.ToLower(strings.ReplaceAll(section, " ", "_")))
+}
+
+// AddSection creates the CSV file of a section and writes its headers.
+func (w *csvReportWriter) AddSection(section string, headers []string) error {
+	if _, ok := w.sections[section]; ok {
+		return nil
+	}
+	file, writer, err := CreateCSVFile(w.csvFilePath(section), headers)
+	if err != nil {
+		return err
+	}
+	w.sections[section] = &csvSection{file: file, writer: writer}
+	return nil
+}
+
+// WriteRow writes a record to the CSV file of a section.
+func (w *csvReportWriter) WriteRow(section string, record []interface{}) error {
+	s, ok := w.sections[section]
+	if !ok {
+		return fmt.Errorf("unknown report section %q", section)
+	}
+	return s.writer.Write(FormatCSVRecord(record))
+}
+
+// Close flushes and closes every CSV file of the report.
+func (w *csvReportWriter) Close() error {
+	var firstErr error
+	for section, s := range w.sections {
+		filePath := w.csvFilePath(section)
+		s.writer.Flush()
+		if err := s.writer.Error(); err != nil {
+			Error("Failed to flush CSV file", zap.String("filePath", filePath), zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		if err := s.file.Close(); err != nil {
+			Error("Failed to close CSV file", zap.String("filePath", filePath), zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		Info("CSV file saved successfully", zap.String("filePath", filePath))
+	}
+	return firstErr
+}
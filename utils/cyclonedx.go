@@ -0,0 +1,130 @@
+// utils/cyclonedx.go
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// CycloneDX 1.5 JSON bill of materials, limited to what an image inventory needs.
+// See https://cyclonedx.org/docs/1.5/json/.
+type (
+	CycloneDXBOM struct {
+		BOMFormat    string               `json:"bomFormat"`
+		SpecVersion  string               `json:"specVersion"`
+		SerialNumber string               `json:"serialNumber"`
+		Version      int                  `json:"version"`
+		Metadata     CycloneDXMetadata    `json:"metadata"`
+		Components   []CycloneDXComponent `json:"components"`
+	}
+
+	CycloneDXMetadata struct {
+		Timestamp string         `json:"timestamp"`
+		Tools     CycloneDXTools `json:"tools"`
+	}
+
+	CycloneDXTools struct {
+		Components []CycloneDXComponent `json:"components"`
+	}
+
+	CycloneDXComponent struct {
+		Type       string              `json:"type"`
+		BOMRef     string              `json:"bom-ref,omitempty"`
+		Name       string              `json:"name"`
+		Version    string              `json:"version,omitempty"`
+		Hashes     []CycloneDXHash     `json:"hashes,omitempty"`
+		PURL       string              `json:"purl,omitempty"`
+		Properties []CycloneDXProperty `json:"properties,omitempty"`
+	}
+
+	CycloneDXHash struct {
+		Algorithm string `json:"alg"`
+		Content   string `json:"content"`
+	}
+
+	CycloneDXProperty struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+)
+
+// cycloneDXHashAlgorithms maps OCI digest algorithms to CycloneDX hash algorithms.
+var cycloneDXHashAlgorithms = map[string]string{
+	"sha256": "SHA-256",
+	"sha384": "SHA-384",
+	"sha512": "SHA-512",
+}
+
+// NewCycloneDXBOM returns an empty bill of materials generated by k8s-reporter now.
+func NewCycloneDXBOM() *CycloneDXBOM {
+	return &CycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + string(uuid.NewUUID()),
+		Version:      1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools: CycloneDXTools{
+				Components: []CycloneDXComponent{{Type: "application", Name: "k8s-reporter"}},
+			},
+		},
+		Components: []CycloneDXComponent{},
+	}
+}
+
+// NewContainerComponent returns the CycloneDX component of a container image, identified by its
+// normalized reference and described by an OCI package URL.
+func NewContainerComponent(reference ImageReference) CycloneDXComponent {
+	component := CycloneDXComponent{
+		Type:    "container",
+		BOMRef:  reference.String(),
+		Name:    reference.Name(),
+		Version: reference.Version(),
+		PURL:    ociPackageURL(reference),
+	}
+	if algorithm, hash, ok := strings.Cut(reference.Digest, ":"); ok && cycloneDXHashAlgorithms[algorithm] != "" {
+		component.Hashes = []CycloneDXHash{{Algorithm: cycloneDXHashAlgorithms[algorithm], Content: hash}}
+	}
+	return component
+}
+
+// ociPackageURL returns the package URL of an image, e.g.
+// "pkg:oci/nginx@sha256%3A...?repository_url=docker.io/library/nginx&tag=1.25".
+// See https://github.com/package-url/purl-spec/blob/master/PURL-TYPES.rst#oci.
+func ociPackageURL(reference ImageReference) string {
+	purl := "pkg:oci/" + path.Base(reference.Repository)
+	if reference.Digest != "" {
+		purl += "@" + strings.ReplaceAll(reference.Digest, ":", "%3A")
+	}
+	purl += "?repository_url=" + reference.Name()
+	if reference.Tag != "" {
+		purl += "&tag=" + url.QueryEscape(reference.Tag)
+	}
+	return purl
+}
+
+// WriteCycloneDXBOM writes a bill of materials to a JSON file.
+func WriteCycloneDXBOM(bom *CycloneDXBOM, fileName string) error {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bom); err != nil {
+		return err
+	}
+	if err := os.WriteFile(fileName, data.Bytes(), 0644); err != nil {
+		Error("Failed to write CycloneDX BOM", zap.String("fileName", fileName), zap.Error(err))
+		return err
+	}
+	Info("CycloneDX BOM written", zap.String("fileName", fileName), zap.Int("components", len(bom.Components)))
+	return nil
+}
@@ -3,6 +3,7 @@
 package utils
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/xuri/excelize/v2"
@@ -73,3 +74,57 @@ func AddSheetToExcelFile(f *excelize.File, sheetName string, headers []string) e
 
 	return nil
 }
+
+// excelReportWriter writes every section of a report to its own sheet of a single Excel file.
+type excelReportWriter struct {
+	filePath string
+	file     *excelize.File
+	nextRow  map[string]int
+}
+
+func newExcelReportWriter(filePath string) (*excelReportWriter, error) {
+	excelManager := GetExcelFileManager()
+	if err := excelManager.OpenOrCreateExcelFile(filePath); err != nil {
+		return nil, err
+	}
+	return &excelReportWriter{filePath: filePath, file: excelManager.GetExcelFile(), nextRow: map[string]int{}}, nil
+}
+
+// AddSection adds a sheet for the section with the given headers.
+func (w *excelReportWriter) AddSection(section string, headers []string) error {
+	if _, ok := w.nextRow[section]; ok {
+		return nil
+	}
+	if err := AddSheetToExcelFile(w.file, section, headers); err != nil {
+		return err
+	}
+	// Starting from the second row, since the first row is for headers
+	w.nextRow[section] = 2
+	return nil
+}
+
+// WriteRow writes a record to the next free row of the section's sheet.
+func (w *excelReportWriter) WriteRow(section string, record []interface{}) error {
+	rowIndex, ok := w.nextRow[section]
+	if !ok {
+		return fmt.Errorf("unknown report section %q", section)
+	}
+	for i, value := range record {
+		cell, err := excelize.CoordinatesToCellName(i+1, rowIndex)
+		if err != nil {
+			Error("Failed to convert coordinates to cell name", zap.String("sheetName", section), zap.Error(err))
+			return err
+		}
+		if err := w.file.SetCellValue(section, cell, value); err != nil {
+			Error("Failed to set cell value", zap.String("cell", cell), zap.String("sheetName", section), zap.Error(err))
+			return err
+		}
+	}
+	w.nextRow[section] = rowIndex + 1
+	return nil
+}
+
+// Close saves the Excel file.
+func (w *excelReportWriter) Close() error {
+	return GetExcelFileManager().SaveExcelFile(w.filePath)
+}
@@ -0,0 +1,72 @@
+// utils/helm.go
+
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/engine"
+	v1 "k8s.io/api/core/v1"
+)
+
+// HelmReleaseName is the release name charts are rendered with, the default of helm template.
+const HelmReleaseName = "release-name"
+
+// RenderHelmChart renders the templates of a local chart (a directory or a .tgz archive) with the
+// given values files, merged in order over the values of the chart, like helm template would without
+// a cluster: the release is installed in the default namespace with the default capabilities.
+// Every template is a manifest whose source is its file in the chart, NOTES.txt and partials excluded.
+func RenderHelmChart(chartPath string, valuesFiles []string) ([]Manifest, error) {
+	chart, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart %s: %w", chartPath, err)
+	}
+	valuesOptions := values.Options{ValueFiles: valuesFiles}
+	userValues, err := valuesOptions.MergeValues(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values of chart %s: %w", chartPath, err)
+	}
+	if err := chartutil.ProcessDependenciesWithMerge(chart, userValues); err != nil {
+		return nil, fmt.Errorf("failed to process dependencies of chart %s: %w", chartPath, err)
+	}
+
+	release := chartutil.ReleaseOptions{Name: HelmReleaseName, Namespace: v1.NamespaceDefault, Revision: 1, IsInstall: true}
+	renderValues, err := chartutil.ToRenderValues(chart, userValues, release, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, fmt.Errorf("invalid values for chart %s: %w", chartPath, err)
+	}
+	templates, err := engine.Render(chart, renderValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render chart %s: %w", chartPath, err)
+	}
+
+	var manifests []Manifest
+	for _, name := range sortedTemplateNames(templates) {
+		content := templates[name]
+		if strings.HasSuffix(name, "NOTES.txt") || strings.HasPrefix(filepath.Base(name), "_") || strings.TrimSpace(content) == "" {
+			continue
+		}
+		// Templates are named after the chart, e.g. "mychart/templates/deployment.yaml"
+		_, path, _ := strings.Cut(name, "/")
+		manifests = append(manifests, Manifest{Source: filepath.Join(chartPath, path), Data: []byte(content)})
+	}
+	Info("Rendered Helm chart", zap.String("chart", chartPath), zap.Strings("values", valuesFiles), zap.Int("templates", len(manifests)))
+	return manifests, nil
+}
+
+// sortedTemplateNames returns the names of the rendered templates in a stable order.
+func sortedTemplateNames(templates map[string]string) []string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,156 @@
+// utils/image.go
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Defaults applied by container runtimes to image references without a registry or a tag.
+const (
+	defaultRegistry   = "docker.io"
+	officialNamespace = "library"
+	latestTag         = "latest"
+)
+
+// Container types reported next to the images of a pod.
+const (
+	ContainerTypeApp     = "app"
+	ContainerTypeInit    = "init"
+	ContainerTypeSidecar = "sidecar"
+)
+
+var (
+	tagPattern        = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	digestPattern     = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-zA-Z0-9=_-]{32,}$`)
+	repositoryPattern = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+)
+
+// ImageReference is a container image reference split into its parts, normalized the way container
+// runtimes do: "nginx" is "docker.io/library/nginx" and has no tag, which pulls "latest".
+type ImageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImageReference splits an image reference like "registry.local:5000/team/app:1.2@sha256:..."
+// into its registry, repository, tag and digest.
+func ParseImageReference(image string) (ImageReference, error) {
+	var ref ImageReference
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.Digest = name[:i], name[i+1:]
+		if !digestPattern.MatchString(ref.Digest) {
+			return ImageReference{}, fmt.Errorf("invalid image reference %q: invalid digest %q", image, ref.Digest)
+		}
+	}
+	// A colon after the last slash separates the tag, a colon before it is part of the registry host:port.
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+		if !tagPattern.MatchString(ref.Tag) {
+			return ImageReference{}, fmt.Errorf("invalid image reference %q: invalid tag %q", image, ref.Tag)
+		}
+	}
+
+	ref.Registry = defaultRegistry
+	ref.Repository = name
+	if i := strings.Index(name, "/"); i >= 0 {
+		host := name[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" || strings.ToLower(host) != host {
+			ref.Registry, ref.Repository = host, name[i+1:]
+		}
+	}
+	if ref.Registry == defaultRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = officialNamespace + "/" + ref.Repository
+	}
+	if !repositoryPattern.MatchString(ref.Repository) {
+		return ImageReference{}, fmt.Errorf("invalid image reference %q: invalid repository %q", image, ref.Repository)
+	}
+	return ref, nil
+}
+
+// Name returns the registry and repository of the image, e.g. "docker.io/library/nginx".
+func (r ImageReference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the normalized reference, e.g. "docker.io/library/nginx:1.25@sha256:...".
+func (r ImageReference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// Version returns the tag of the image, its digest when it is only pinned by digest, or "latest"
+// when it has neither, since that is what gets pulled.
+func (r ImageReference) Version() string {
+	switch {
+	case r.Tag != "":
+		return r.Tag
+	case r.Digest != "":
+		return r.Digest
+	default:
+		return latestTag
+	}
+}
+
+// Untagged reports whether the reference has no tag.
+func (r ImageReference) Untagged() bool {
+	return r.Tag == ""
+}
+
+// Latest reports whether the image floats with the "latest" tag, explicitly or because it has
+// neither a tag nor a digest. An image pinned by digest never floats.
+func (r ImageReference) Latest() bool {
+	return r.Digest == "" && (r.Tag == "" || r.Tag == latestTag)
+}
+
+// ContainerImage is the image of a single container of a pod.
+type ContainerImage struct {
+	Container  string
+	Type       string
+	Image      string
+	PullPolicy v1.PullPolicy
+	Reference  ImageReference
+	// Err is set when the image reference cannot be parsed, leaving Reference empty.
+	Err error
+}
+
+// ExtractContainerImages returns the image of every container of a pod: app containers followed by
+// init containers and sidecars.
+func ExtractContainerImages(podSpec v1.PodSpec) []ContainerImage {
+	var images []ContainerImage
+	add := func(container v1.Container, containerType string) {
+		reference, err := ParseImageReference(container.Image)
+		images = append(images, ContainerImage{
+			Container:  container.Name,
+			Type:       containerType,
+			Image:      container.Image,
+			PullPolicy: container.ImagePullPolicy,
+			Reference:  reference,
+			Err:        err,
+		})
+	}
+	for _, container := range podSpec.Containers {
+		add(container, ContainerTypeApp)
+	}
+	for _, container := range podSpec.InitContainers {
+		if isSidecar(container) {
+			add(container, ContainerTypeSidecar)
+		} else {
+			add(container, ContainerTypeInit)
+		}
+	}
+	return images
+}
@@ -0,0 +1,82 @@
+// utils/image_test.go
+
+package utils
+
+import "testing"
+
+func TestParseImageReference(t *testing.T) {
+	const digest = "sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"
+	tests := []struct {
+		image    string
+		want     ImageReference
+		version  string
+		latest   bool
+		untagged bool
+	}{
+		{"nginx", ImageReference{Registry: "docker.io", Repository: "library/nginx"}, "latest", true, true},
+		{"nginx:latest", ImageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"}, "latest", true, false},
+		{"nginx:1.25", ImageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}, "1.25", false, false},
+		{"bitnami/redis:7.2", ImageReference{Registry: "docker.io", Repository: "bitnami/redis", Tag: "7.2"}, "7.2", false, false},
+		{"docker.io/library/nginx:1.25", ImageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}, "1.25", false, false},
+		{"registry.local:5000/app:1.2", ImageReference{Registry: "registry.local:5000", Repository: "app", Tag: "1.2"}, "1.2", false, false},
+		{"registry.local:5000/team/app", ImageReference{Registry: "registry.local:5000", Repository: "team/app"}, "latest", true, true},
+		{"localhost/x", ImageReference{Registry: "localhost", Repository: "x"}, "latest", true, true},
+		{"localhost:5000/x:dev", ImageReference{Registry: "localhost:5000", Repository: "x", Tag: "dev"}, "dev", false, false},
+		{"ghcr.io/acme/api@" + digest, ImageReference{Registry: "ghcr.io", Repository: "acme/api", Digest: digest}, digest, false, true},
+		{"nginx:1.25@" + digest, ImageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25", Digest: digest}, "1.25", false, false},
+		{"nginx:latest@" + digest, ImageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "latest", Digest: digest}, "latest", false, false},
+		{"registry.local:5000/app:1.2@" + digest, ImageReference{Registry: "registry.local:5000", Repository: "app", Tag: "1.2", Digest: digest}, "1.2", false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.image, func(t *testing.T) {
+			got, err := ParseImageReference(test.image)
+			if err != nil {
+				t.Fatalf("ParseImageReference: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+			if got.Version() != test.version {
+				t.Errorf("version is %q, want %q", got.Version(), test.version)
+			}
+			if got.Latest() != test.latest {
+				t.Errorf("latest is %v, want %v", got.Latest(), test.latest)
+			}
+			if got.Untagged() != test.untagged {
+				t.Errorf("untagged is %v, want %v", got.Untagged(), test.untagged)
+			}
+		})
+	}
+}
+
+func TestParseImageReferenceNormalizes(t *testing.T) {
+	for image, want := range map[string]string{
+		"nginx":                       "docker.io/library/nginx",
+		"nginx:1.25":                  "docker.io/library/nginx:1.25",
+		"registry.local:5000/app:1.2": "registry.local:5000/app:1.2",
+	} {
+		reference, err := ParseImageReference(image)
+		if err != nil {
+			t.Fatalf("ParseImageReference(%q): %v", image, err)
+		}
+		if reference.String() != want {
+			t.Errorf("%q is normalized to %q, want %q", image, reference.String(), want)
+		}
+	}
+}
+
+func TestParseImageReferenceErrors(t *testing.T) {
+	for _, image := range []string{
+		"",
+		"Nginx",
+		"nginx:",
+		"nginx:-bad",
+		"nginx@sha256:short",
+		"nginx@" + "md5",
+		"registry.local:5000/",
+	} {
+		if reference, err := ParseImageReference(image); err == nil {
+			t.Errorf("ParseImageReference(%q) = %+v, want an error", image, reference)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// utils/json_writer.go
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+)
+
+// documentReportWriter collects the whole report in memory and writes it as a single
+// document mapping every section to the list of its rows, e.g. {"Deployments": [{...}]}.
+type documentReportWriter struct {
+	filePath string
+	marshal  func(document map[string][]map[string]interface{}) ([]byte, error)
+	headers  map[string][]string
+	document map[string][]map[string]interface{}
+}
+
+func newDocumentReportWriter(filePath string, marshal func(map[string][]map[string]interface{}) ([]byte, error)) *documentReportWriter {
+	return &documentReportWriter{
+		filePath: filePath,
+		marshal:  marshal,
+		headers:  map[string][]string{},
+		document: map[string][]map[string]interface{}{},
+	}
+}
+
+func marshalJSONDocument(document map[string][]map[string]interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func marshalYAMLDocument(document map[string][]map[string]interface{}) ([]byte, error) {
+	return yaml.Marshal(document)
+}
+
+// AddSection registers the headers of a section.
+func (w *documentReportWriter) AddSection(section string, headers []string) error {
+	if _, ok := w.headers[section]; ok {
+		return nil
+	}
+	w.headers[section] = headers
+	w.document[section] = []map[string]interface{}{}
+	return nil
+}
+
+// WriteRow adds a record to a section as an object keyed by the section headers.
+func (w *documentReportWriter) WriteRow(section string, record []interface{}) error {
+	headers, ok := w.headers[section]
+	if !ok {
+		return fmt.Errorf("unknown report section %q", section)
+	}
+	w.document[section] = append(w.document[section], recordToObject(headers, record))
+	return nil
+}
+
+// Close marshals the collected report and writes it to the report file.
+func (w *documentReportWriter) Close() error {
+	data, err := w.marshal(w.document)
+	if err != nil {
+		Error("Failed to marshal the report", zap.String("filePath", w.filePath), zap.Error(err))
+		return err
+	}
+	if err := os.WriteFile(w.filePath, data, 0644); err != nil {
+		Error("Failed to write the report", zap.String("filePath", w.filePath), zap.Error(err))
+		return err
+	}
+	Info("Report saved successfully", zap.String("filePath", w.filePath))
+	return nil
+}
+
+// ndjsonReportWriter streams every row as one JSON object per line. The section
+// of each row is stored under the "Section" key.
+type ndjsonReportWriter struct {
+	filePath string
+	file     *os.File
+	encoder  *json.Encoder
+	headers  map[string][]string
+}
+
+func newNDJSONReportWriter(filePath string) (*ndjsonReportWriter, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		Error("Failed to create NDJSON file", zap.String("filePath", filePath), zap.Error(err))
+		return nil, err
+	}
+	return &ndjsonReportWriter{filePath: filePath, file: file, encoder: json.NewEncoder(file), headers: map[string][]string{}}, nil
+}
+
+// AddSection registers the headers of a section.
+func (w *ndjsonReportWriter) AddSection(section string, headers []string) error {
+	if _, ok := w.headers[section]; !ok {
+		w.headers[section] = headers
+	}
+	return nil
+}
+
+// WriteRow writes a record as a single JSON line.
+func (w *ndjsonReportWriter) WriteRow(section string, record []interface{}) error {
+	headers, ok := w.headers[section]
+	if !ok {
+		return fmt.Errorf("unknown report section %q", section)
+	}
+	object := recordToObject(headers, record)
+	object["Section"] = section
+	return w.encoder.Encode(object)
+}
+
+// Close closes the NDJSON file.
+func (w *ndjsonReportWriter) Close() error {
+	if err := w.file.Close(); err != nil {
+		Error("Failed to close NDJSON file", zap.String("filePath", w.filePath), zap.Error(err))
+		return err
+	}
+	Info("Report saved successfully", zap.String("filePath", w.filePath))
+	return nil
+}
@@ -7,17 +7,24 @@ import (
 
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
-// GetKubernetesClient initializes a Kubernetes clientset from the default kubeconfig path.
-func GetKubernetesClient(kubeconfigPath string) (*kubernetes.Clientset, error) {
+// getRESTConfig builds the client configuration from the given kubeconfig, or from the default kubeconfig path.
+func getRESTConfig(kubeconfigPath string) (*rest.Config, error) {
 	if kubeconfigPath == "" {
 		kubeconfigPath = filepath.Join(homedir.HomeDir(), ".kube", "config")
 	}
 
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	return clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+}
+
+// GetKubernetesClient initializes a Kubernetes clientset from the default kubeconfig path.
+func GetKubernetesClient(kubeconfigPath string) (kubernetes.Interface, error) {
+	config, err := getRESTConfig(kubeconfigPath)
 	if err != nil {
 		return nil, err
 	}
@@ -29,3 +36,13 @@ func GetKubernetesClient(kubeconfigPath string) (*kubernetes.Clientset, error) {
 
 	return clientset, nil
 }
+
+// GetMetricsClient initializes a clientset for the metrics.k8s.io API from the default kubeconfig path.
+func GetMetricsClient(kubeconfigPath string) (metricsclientset.Interface, error) {
+	config, err := getRESTConfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return metricsclientset.NewForConfig(config)
+}
@@ -0,0 +1,39 @@
+// utils/kustomize.go
+
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// RenderKustomization builds a local kustomization directory, like kustomize build with its default
+// options (no plugins, no files outside the directory). Every resource is a manifest whose source is
+// its original file when the kustomization records origin annotations (buildMetadata: [originAnnotations]),
+// or the kustomization directory otherwise.
+func RenderKustomization(path string) ([]Manifest, error) {
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resources, err := kustomizer.Run(filesys.MakeFsOnDisk(), path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kustomization %s: %w", path, err)
+	}
+
+	var manifests []Manifest
+	for _, resource := range resources.Resources() {
+		source := path
+		if origin, err := resource.GetOrigin(); err == nil && origin != nil && origin.Path != "" && origin.Repo == "" {
+			source = filepath.Join(path, origin.Path)
+		}
+		data, err := resource.AsYAML()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build kustomization %s: %w", path, err)
+		}
+		manifests = append(manifests, Manifest{Source: source, Data: data})
+	}
+	Info("Built kustomization", zap.String("path", path), zap.Int("resources", len(manifests)))
+	return manifests, nil
+}
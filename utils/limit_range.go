@@ -0,0 +1,144 @@
+// utils/limit_range.go
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// applyLimitRangeDefaults returns the containers with the requests and limits they get once the pod is
+// admitted: a missing request defaults to the container limit (API server defaulting), then missing
+// requests and limits are filled from the Container defaults of every LimitRange of the namespace, in
+// order, like the LimitRanger admission plugin does. It reports whether any LimitRange default was used.
+func applyLimitRangeDefaults(containers []v1.Container, limitRanges []v1.LimitRange) ([]v1.Container, bool) {
+	defaulted := false
+	result := make([]v1.Container, len(containers))
+	for i, container := range containers {
+		requests := container.Resources.Requests.DeepCopy()
+		if requests == nil {
+			requests = v1.ResourceList{}
+		}
+		limits := container.Resources.Limits.DeepCopy()
+		if limits == nil {
+			limits = v1.ResourceList{}
+		}
+
+		for name, limit := range limits {
+			if _, ok := requests[name]; !ok {
+				requests[name] = limit.DeepCopy()
+			}
+		}
+
+		for _, limitRange := range limitRanges {
+			for _, item := range limitRange.Spec.Limits {
+				if item.Type != v1.LimitTypeContainer {
+					continue
+				}
+				for name, value := range item.Default {
+					if _, ok := limits[name]; !ok {
+						limits[name] = value.DeepCopy()
+						defaulted = true
+					}
+				}
+				for name, value := range item.DefaultRequest {
+					if _, ok := requests[name]; !ok {
+						requests[name] = value.DeepCopy()
+						defaulted = true
+					}
+				}
+			}
+		}
+
+		container.Resources.Requests = requests
+		container.Resources.Limits = limits
+		result[i] = container
+	}
+	return result, defaulted
+}
+
+// validateLimitRanges returns the Min, Max and MaxLimitRequestRatio constraints of the LimitRanges
+// the pod would violate, worded like the errors of the LimitRanger admission plugin.
+func validateLimitRanges(limitRanges []v1.LimitRange, containers []v1.Container, podRequests v1.ResourceList, podLimits v1.ResourceList) []string {
+	var violations []string
+	for _, limitRange := range limitRanges {
+		for _, item := range limitRange.Spec.Limits {
+			switch item.Type {
+			case v1.LimitTypeContainer:
+				for _, container := range containers {
+					for _, violation := range checkLimitRangeItem(item, container.Resources.Requests, container.Resources.Limits) {
+						violations = append(violations, fmt.Sprintf("container %s: %s", container.Name, violation))
+					}
+				}
+			case v1.LimitTypePod:
+				violations = append(violations, checkLimitRangeItem(item, podRequests, podLimits)...)
+			}
+		}
+	}
+	return violations
+}
+
+// checkLimitRangeItem checks requests and limits against the constraints of a single LimitRange item.
+func checkLimitRangeItem(item v1.LimitRangeItem, requests v1.ResourceList, limits v1.ResourceList) []string {
+	var violations []string
+	limitType := string(item.Type)
+
+	for _, name := range sortedResourceNames(item.Min) {
+		minimum := item.Min[name]
+		request, hasRequest := requests[name]
+		limit, hasLimit := limits[name]
+		if !hasRequest {
+			violations = append(violations, fmt.Sprintf("minimum %s usage per %s is %s, but no request is specified", name, limitType, minimum.String()))
+		} else if request.Cmp(minimum) < 0 {
+			violations = append(violations, fmt.Sprintf("minimum %s usage per %s is %s, but request is %s", name, limitType, minimum.String(), request.String()))
+		}
+		if hasLimit && limit.Cmp(minimum) < 0 {
+			violations = append(violations, fmt.Sprintf("minimum %s usage per %s is %s, but limit is %s", name, limitType, minimum.String(), limit.String()))
+		}
+	}
+
+	for _, name := range sortedResourceNames(item.Max) {
+		maximum := item.Max[name]
+		request, hasRequest := requests[name]
+		limit, hasLimit := limits[name]
+		if !hasLimit {
+			violations = append(violations, fmt.Sprintf("maximum %s usage per %s is %s, but no limit is specified", name, limitType, maximum.String()))
+		} else if limit.Cmp(maximum) > 0 {
+			violations = append(violations, fmt.Sprintf("maximum %s usage per %s is %s, but limit is %s", name, limitType, maximum.String(), limit.String()))
+		}
+		if hasRequest && request.Cmp(maximum) > 0 {
+			violations = append(violations, fmt.Sprintf("maximum %s usage per %s is %s, but request is %s", name, limitType, maximum.String(), request.String()))
+		}
+	}
+
+	for _, name := range sortedResourceNames(item.MaxLimitRequestRatio) {
+		maxRatio := item.MaxLimitRequestRatio[name]
+		request, hasRequest := requests[name]
+		limit, hasLimit := limits[name]
+		if !hasRequest || request.IsZero() {
+			violations = append(violations, fmt.Sprintf("maximum %s limit to request ratio per %s is %s, but no request is specified or request is 0", name, limitType, maxRatio.String()))
+			continue
+		}
+		if !hasLimit {
+			violations = append(violations, fmt.Sprintf("maximum %s limit to request ratio per %s is %s, but no limit is specified", name, limitType, maxRatio.String()))
+			continue
+		}
+		ratio := float64(limit.MilliValue()) / float64(request.MilliValue())
+		if ratio > maxRatio.AsApproximateFloat64() {
+			violations = append(violations, fmt.Sprintf("maximum %s limit to request ratio per %s is %s, but provided ratio is %.2f", name, limitType, maxRatio.String(), ratio))
+		}
+	}
+	return violations
+}
+
+// sortedResourceNames returns the resource names of a list in a stable order.
+func sortedResourceNames(list v1.ResourceList) []v1.ResourceName {
+	names := make([]v1.ResourceName, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
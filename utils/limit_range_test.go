@@ -0,0 +1,225 @@
+// utils/limit_range_test.go
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// resourceList builds a list of CPU and memory, leaving out the empty values.
+func resourceList(cpu string, memory string) v1.ResourceList {
+	list := v1.ResourceList{}
+	if cpu != "" {
+		list[v1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		list[v1.ResourceMemory] = resource.MustParse(memory)
+	}
+	return list
+}
+
+func containerLimitRange(item v1.LimitRangeItem) v1.LimitRange {
+	item.Type = v1.LimitTypeContainer
+	return v1.LimitRange{Spec: v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{item}}}
+}
+
+// sameResources reports whether two lists have the same quantities, whatever their format.
+func sameResources(a v1.ResourceList, b v1.ResourceList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, quantity := range a {
+		other, ok := b[name]
+		if !ok || quantity.Cmp(other) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestApplyLimitRangeDefaults(t *testing.T) {
+	defaults := containerLimitRange(v1.LimitRangeItem{
+		Default:        resourceList("500m", "512Mi"),
+		DefaultRequest: resourceList("100m", "128Mi"),
+	})
+	tests := []struct {
+		name          string
+		requests      v1.ResourceList
+		limits        v1.ResourceList
+		limitRanges   []v1.LimitRange
+		wantRequests  v1.ResourceList
+		wantLimits    v1.ResourceList
+		wantDefaulted bool
+	}{
+		{
+			name:         "no LimitRange",
+			wantRequests: v1.ResourceList{},
+			wantLimits:   v1.ResourceList{},
+		},
+		{
+			name:         "request defaults to the limit without a LimitRange",
+			limits:       resourceList("1", "1Gi"),
+			wantRequests: resourceList("1", "1Gi"),
+			wantLimits:   resourceList("1", "1Gi"),
+		},
+		{
+			name:          "default and defaultRequest fill limits and requests",
+			limitRanges:   []v1.LimitRange{defaults},
+			wantRequests:  resourceList("100m", "128Mi"),
+			wantLimits:    resourceList("500m", "512Mi"),
+			wantDefaulted: true,
+		},
+		{
+			name:          "request defaults to the container limit before defaultRequest",
+			limits:        resourceList("2", ""),
+			limitRanges:   []v1.LimitRange{defaults},
+			wantRequests:  resourceList("2", "128Mi"),
+			wantLimits:    resourceList("2", "512Mi"),
+			wantDefaulted: true,
+		},
+		{
+			name:         "set values are kept",
+			requests:     resourceList("50m", "64Mi"),
+			limits:       resourceList("1", "1Gi"),
+			limitRanges:  []v1.LimitRange{defaults},
+			wantRequests: resourceList("50m", "64Mi"),
+			wantLimits:   resourceList("1", "1Gi"),
+		},
+		{
+			name:          "the first LimitRange setting a default wins",
+			limitRanges:   []v1.LimitRange{containerLimitRange(v1.LimitRangeItem{DefaultRequest: resourceList("200m", "")}), defaults},
+			wantRequests:  resourceList("200m", "128Mi"),
+			wantLimits:    resourceList("500m", "512Mi"),
+			wantDefaulted: true,
+		},
+		{
+			name: "Pod items have no defaults",
+			limitRanges: []v1.LimitRange{{Spec: v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{
+				{Type: v1.LimitTypePod, Max: resourceList("4", "8Gi")},
+			}}}},
+			wantRequests: v1.ResourceList{},
+			wantLimits:   v1.ResourceList{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			container := v1.Container{Name: "app", Resources: v1.ResourceRequirements{Requests: test.requests, Limits: test.limits}}
+			original := container.Resources.Requests.DeepCopy()
+			containers, defaulted := applyLimitRangeDefaults([]v1.Container{container}, test.limitRanges)
+			if defaulted != test.wantDefaulted {
+				t.Errorf("defaulted is %v, want %v", defaulted, test.wantDefaulted)
+			}
+			if got := containers[0].Resources.Requests; !sameResources(got, test.wantRequests) {
+				t.Errorf("requests are %v, want %v", got, test.wantRequests)
+			}
+			if got := containers[0].Resources.Limits; !sameResources(got, test.wantLimits) {
+				t.Errorf("limits are %v, want %v", got, test.wantLimits)
+			}
+			if !reflect.DeepEqual(container.Resources.Requests, original) {
+				t.Errorf("the given container was modified")
+			}
+		})
+	}
+}
+
+func TestValidateLimitRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     v1.LimitRangeItem
+		requests v1.ResourceList
+		limits   v1.ResourceList
+		want     []string
+	}{
+		{
+			name:     "within min and max",
+			item:     v1.LimitRangeItem{Type: v1.LimitTypeContainer, Min: resourceList("50m", ""), Max: resourceList("2", "")},
+			requests: resourceList("100m", ""),
+			limits:   resourceList("1", ""),
+		},
+		{
+			name:     "below min",
+			item:     v1.LimitRangeItem{Type: v1.LimitTypeContainer, Min: resourceList("", "64Mi")},
+			requests: resourceList("", "32Mi"),
+			limits:   resourceList("", "48Mi"),
+			want: []string{
+				"container app: minimum memory usage per Container is 64Mi, but request is 32Mi",
+				"container app: minimum memory usage per Container is 64Mi, but limit is 48Mi",
+			},
+		},
+		{
+			name: "min without request",
+			item: v1.LimitRangeItem{Type: v1.LimitTypeContainer, Min: resourceList("50m", "")},
+			want: []string{"container app: minimum cpu usage per Container is 50m, but no request is specified"},
+		},
+		{
+			name:     "above max",
+			item:     v1.LimitRangeItem{Type: v1.LimitTypeContainer, Max: resourceList("1", "")},
+			requests: resourceList("1500m", ""),
+			limits:   resourceList("2", ""),
+			want: []string{
+				"container app: maximum cpu usage per Container is 1, but limit is 2",
+				"container app: maximum cpu usage per Container is 1, but request is 1500m",
+			},
+		},
+		{
+			name:     "max without limit",
+			item:     v1.LimitRangeItem{Type: v1.LimitTypeContainer, Max: resourceList("", "1Gi")},
+			requests: resourceList("", "256Mi"),
+			want:     []string{"container app: maximum memory usage per Container is 1Gi, but no limit is specified"},
+		},
+		{
+			name:     "ratio within maxLimitRequestRatio",
+			item:     v1.LimitRangeItem{Type: v1.LimitTypeContainer, MaxLimitRequestRatio: resourceList("4", "")},
+			requests: resourceList("250m", ""),
+			limits:   resourceList("1", ""),
+		},
+		{
+			name:     "ratio above maxLimitRequestRatio",
+			item:     v1.LimitRangeItem{Type: v1.LimitTypeContainer, MaxLimitRequestRatio: resourceList("2", "")},
+			requests: resourceList("250m", ""),
+			limits:   resourceList("1", ""),
+			want:     []string{"container app: maximum cpu limit to request ratio per Container is 2, but provided ratio is 4.00"},
+		},
+		{
+			name:   "maxLimitRequestRatio without request",
+			item:   v1.LimitRangeItem{Type: v1.LimitTypeContainer, MaxLimitRequestRatio: resourceList("", "2")},
+			limits: resourceList("", "1Gi"),
+			want:   []string{"container app: maximum memory limit to request ratio per Container is 2, but no request is specified or request is 0"},
+		},
+		{
+			name:     "maxLimitRequestRatio without limit",
+			item:     v1.LimitRangeItem{Type: v1.LimitTypeContainer, MaxLimitRequestRatio: resourceList("", "2")},
+			requests: resourceList("", "1Gi"),
+			want:     []string{"container app: maximum memory limit to request ratio per Container is 2, but no limit is specified"},
+		},
+		{
+			name:     "Pod items check the pod totals",
+			item:     v1.LimitRangeItem{Type: v1.LimitTypePod, Max: resourceList("1", "")},
+			requests: resourceList("500m", ""),
+			limits:   resourceList("1", ""),
+			want:     []string{"maximum cpu usage per Pod is 1, but limit is 2"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			limitRanges := []v1.LimitRange{{Spec: v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{test.item}}}}
+			container := v1.Container{Name: "app", Resources: v1.ResourceRequirements{Requests: test.requests, Limits: test.limits}}
+			// the pod runs the container twice, e.g. as an app container and a sidecar
+			podRequests, podLimits := v1.ResourceList{}, v1.ResourceList{}
+			for name, quantity := range test.requests {
+				podRequests[name] = MultiplyQuantity(quantity, 2)
+			}
+			for name, quantity := range test.limits {
+				podLimits[name] = MultiplyQuantity(quantity, 2)
+			}
+			got := validateLimitRanges(limitRanges, []v1.Container{container}, podRequests, podLimits)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("violations are %q, want %q", got, test.want)
+			}
+		})
+	}
+}
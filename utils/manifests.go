@@ -0,0 +1,218 @@
+// utils/manifests.go
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// StdinManifests is the --from-files path reading the manifests from the standard input.
+const StdinManifests = "-"
+
+// manifestExtensions are the extensions of the files read from a directory.
+var manifestExtensions = map[string]bool{".yaml": true, ".yml": true, ".json": true}
+
+// clusterScopedKinds are the kinds, among those k8s-reporter reads, that have no namespace.
+var clusterScopedKinds = map[string]bool{
+	"Namespace":          true,
+	"Node":               true,
+	"PersistentVolume":   true,
+	"StorageClass":       true,
+	"PriorityClass":      true,
+	"RuntimeClass":       true,
+	"ClusterRole":        true,
+	"ClusterRoleBinding": true,
+}
+
+// Manifest is a YAML or JSON document, or several, rendered from a Helm chart or a Kustomize overlay.
+type Manifest struct {
+	// Source is the file the manifest was rendered from, e.g. the template of a chart.
+	Source string
+	Data   []byte
+}
+
+// manifestLoader decodes manifests into the object tracker of a fake clientset.
+type manifestLoader struct {
+	clientset *fake.Clientset
+	objects   int
+	skipped   int
+	// daemonSets counts the DaemonSets, whose pods are only known from their status.
+	daemonSets int
+}
+
+// LoadManifests reads Kubernetes manifests from files and directories (YAML or JSON, multi-document,
+// and List dumps like the output of kubectl get -o yaml) into an in-memory clientset, so that the
+// handlers report on them as they would on a cluster. Every object is annotated with its file
+// (SourceAnnotation), and namespaced objects without a namespace land in "default", as with kubectl apply.
+// Kinds client-go does not know, e.g. custom resources, are skipped. Rendered manifests are loaded
+// after the files.
+func LoadManifests(paths []string, rendered []Manifest) (kubernetes.Interface, error) {
+	loader := &manifestLoader{clientset: fake.NewSimpleClientset()}
+	for _, path := range paths {
+		if path == StdinManifests {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return nil, err
+			}
+			if err := loader.loadData(data, "stdin"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if err := loader.loadFile(path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || !manifestExtensions[strings.ToLower(filepath.Ext(file))] {
+				return nil
+			}
+			return loader.loadFile(file)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, manifest := range rendered {
+		if err := loader.loadData(manifest.Data, manifest.Source); err != nil {
+			return nil, err
+		}
+	}
+	Info("Loaded manifests", zap.Strings("paths", paths), zap.Int("rendered", len(rendered)), zap.Int("objects", loader.objects), zap.Int("skipped", loader.skipped))
+	if loader.daemonSets > 0 {
+		Warn("DaemonSets loaded from manifests have no scheduled pods: their replicas, totals and costs are 0", zap.Int("daemonSets", loader.daemonSets))
+	}
+	return loader.clientset, nil
+}
+
+func (l *manifestLoader) loadFile(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return l.loadData(data, file)
+}
+
+// loadData splits the YAML documents of a file, or its JSON object, and adds their objects.
+func (l *manifestLoader) loadData(data []byte, source string) error {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for document := 1; ; document++ {
+		content, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid manifest %s: %w", source, err)
+		}
+		jsonContent, err := yaml.YAMLToJSON(content)
+		if err != nil {
+			return fmt.Errorf("invalid manifest %s (document %d): %w", source, document, err)
+		}
+		if trimmed := bytes.TrimSpace(jsonContent); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+			continue
+		}
+		if err := l.add(jsonContent, source); err != nil {
+			return fmt.Errorf("invalid manifest %s (document %d): %w", source, document, err)
+		}
+	}
+}
+
+// add decodes a single object, or the items of a list, and adds them to the clientset.
+func (l *manifestLoader) add(data []byte, source string) error {
+	object, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(data, nil, nil)
+	if runtime.IsNotRegisteredError(err) || runtime.IsMissingKind(err) {
+		Info("Skipping manifest of an unsupported kind", zap.String("source", source), zap.Error(err))
+		l.skipped++
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// kind: List, whose items may be of any kind
+	if list, ok := object.(*v1.List); ok {
+		for _, item := range list.Items {
+			if err := l.add(item.Raw, source); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// typed lists, e.g. kind: DeploymentList
+	if meta.IsListType(object) {
+		items, err := meta.ExtractList(object)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			item.GetObjectKind().SetGroupVersionKind(gvk.GroupVersion().WithKind(strings.TrimSuffix(gvk.Kind, "List")))
+			if err := l.addObject(item, gvk.Kind, source); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return l.addObject(object, gvk.Kind, source)
+}
+
+func (l *manifestLoader) addObject(object runtime.Object, kind string, source string) error {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return err
+	}
+	if accessor.GetNamespace() == "" && !clusterScopedKinds[strings.TrimSuffix(kind, "List")] {
+		accessor.SetNamespace(v1.NamespaceDefault)
+	}
+	annotations := accessor.GetAnnotations()
+	if annotations[SourceAnnotation] == "" {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[SourceAnnotation] = source
+		accessor.SetAnnotations(annotations)
+	}
+
+	if err := l.clientset.Tracker().Add(object); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			Warn("Skipping duplicate manifest", zap.String("source", source), zap.String("kind", object.GetObjectKind().GroupVersionKind().Kind),
+				zap.String("namespace", accessor.GetNamespace()), zap.String("name", accessor.GetName()))
+			l.skipped++
+			return nil
+		}
+		return err
+	}
+	l.objects++
+	if strings.TrimSuffix(kind, "List") == "DaemonSet" {
+		l.daemonSets++
+	}
+	return nil
+}
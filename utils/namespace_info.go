@@ -4,107 +4,85 @@ package utils
 
 import (
 	"context"
-	"fmt"
+	"sync"
 
 	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-// getLimitRangeItems fetches the LimitRange items for a given namespace using an existing clientset.
-func getLimitRangeItems(clientset *kubernetes.Clientset, namespace string) ([]v1.LimitRangeItem, error) {
-	limitRanges, err := clientset.CoreV1().LimitRanges(namespace).List(context.Background(), metav1.ListOptions{})
-	if err != nil {
-		if errors.IsNotFound(err) {
-			Info("No LimitRange found in namespace", zap.String("namespace", namespace))
-		} else {
-			Error("Error getting LimitRange for namespace", zap.String("namespace", namespace), zap.Error(err))
-		}
-		return nil, err
-	}
-
-	if len(limitRanges.Items) == 0 {
-		return nil, fmt.Errorf("no LimitRange items found in namespace: %s", namespace)
-	}
-
-	return limitRanges.Items[0].Spec.Limits, nil
+// limitRangeCache holds the LimitRanges of every namespace, so that all handlers share
+// a single cluster-wide list instead of listing them again for every workload.
+type limitRangeCache struct {
+	once        sync.Once
+	mutex       sync.Mutex
+	prefetched  bool
+	limitRanges map[string][]v1.LimitRange
 }
 
-// GetNamespaceDefaultCPURequests retrieves the default CPU request for a namespace using an existing clientset.
-func GetNamespaceDefaultCPURequests(clientset *kubernetes.Clientset, namespace string) resource.Quantity {
-	limitItems, err := getLimitRangeItems(clientset, namespace)
-	if err != nil {
-		return resource.Quantity{}
-	}
+var limitRanges = &limitRangeCache{limitRanges: map[string][]v1.LimitRange{}}
 
-	for _, item := range limitItems {
-		if item.Type == v1.LimitTypeContainer {
-			if val, ok := item.DefaultRequest[v1.ResourceCPU]; ok {
-				return val
-			}
+// prefetch lists the LimitRanges of all namespaces with a single API call.
+func (c *limitRangeCache) prefetch(clientset kubernetes.Interface) {
+	c.once.Do(func() {
+		list, err := clientset.CoreV1().LimitRanges("").List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			// e.g. no permission to list cluster-wide, fall back to listing per namespace
+			Warn("Failed to list LimitRanges across all namespaces, listing them per namespace", zap.Error(err))
+			return
 		}
-	}
 
-	Info("Default CPU request not found in namespace", zap.String("namespace", namespace))
-	return resource.Quantity{}
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		for _, limitRange := range list.Items {
+			c.limitRanges[limitRange.Namespace] = append(c.limitRanges[limitRange.Namespace], limitRange)
+		}
+		c.prefetched = true
+		Info("Prefetched LimitRanges", zap.Int("count", len(list.Items)))
+	})
 }
 
-// GetNamespaceDefaultMemoryRequests retrieves the default Memory request for a namespace using an existing clientset.
-func GetNamespaceDefaultMemoryRequests(clientset *kubernetes.Clientset, namespace string) resource.Quantity {
-	limitItems, err := getLimitRangeItems(clientset, namespace)
-	if err != nil {
-		return resource.Quantity{}
-	}
+// get returns the LimitRanges of a namespace, listing them only if they were not prefetched or cached yet.
+func (c *limitRangeCache) get(clientset kubernetes.Interface, namespace string) ([]v1.LimitRange, error) {
+	c.prefetch(clientset)
 
-	for _, item := range limitItems {
-		if item.Type == v1.LimitTypeContainer {
-			if val, ok := item.DefaultRequest[v1.ResourceMemory]; ok {
-				return val
-			}
-		}
+	c.mutex.Lock()
+	items, ok := c.limitRanges[namespace]
+	prefetched := c.prefetched
+	c.mutex.Unlock()
+	if ok || prefetched {
+		return items, nil
 	}
 
-	Info("Default Memory request not found in namespace", zap.String("namespace", namespace))
-	return resource.Quantity{}
-}
-
-// GetNamespaceDefaultCPULimits retrieves the default CPU limit for a namespace using an existing clientset.
-func GetNamespaceDefaultCPULimits(clientset *kubernetes.Clientset, namespace string) resource.Quantity {
-	limitItems, err := getLimitRangeItems(clientset, namespace)
+	list, err := clientset.CoreV1().LimitRanges(namespace).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
-		return resource.Quantity{}
-	}
-
-	for _, item := range limitItems {
-		if item.Type == v1.LimitTypeContainer {
-			if val, ok := item.Default[v1.ResourceCPU]; ok {
-				return val
-			}
+		if errors.IsNotFound(err) {
+			Info("No LimitRange found in namespace", zap.String("namespace", namespace))
+		} else {
+			Error("Error getting LimitRange for namespace", zap.String("namespace", namespace), zap.Error(err))
 		}
+		// Remember the failure so the other workloads of the namespace do not retry it
+		c.mutex.Lock()
+		c.limitRanges[namespace] = nil
+		c.mutex.Unlock()
+		return nil, err
 	}
 
-	Info("Default CPU limit not found in namespace", zap.String("namespace", namespace))
-	return resource.Quantity{}
+	c.mutex.Lock()
+	c.limitRanges[namespace] = list.Items
+	c.mutex.Unlock()
+	return list.Items, nil
 }
 
-// GetNamespaceDefaultMemoryLimits retrieves the default Memory limit for a namespace using an existing clientset.
-func GetNamespaceDefaultMemoryLimits(clientset *kubernetes.Clientset, namespace string) resource.Quantity {
-	limitItems, err := getLimitRangeItems(clientset, namespace)
-	if err != nil {
-		return resource.Quantity{}
-	}
-
-	for _, item := range limitItems {
-		if item.Type == v1.LimitTypeContainer {
-			if val, ok := item.Default[v1.ResourceMemory]; ok {
-				return val
-			}
-		}
-	}
+// PrefetchNamespaceDefaults loads the LimitRanges of every namespace with a single API call, once per run.
+// The commands call it when they build their clientset; GetNamespaceLimitRanges still calls it lazily.
+func PrefetchNamespaceDefaults(clientset kubernetes.Interface) {
+	limitRanges.prefetch(clientset)
+}
 
-	Info("Default Memory limit not found in namespace", zap.String("namespace", namespace))
-	return resource.Quantity{}
+// GetNamespaceLimitRanges returns every LimitRange of a namespace from the LimitRange cache.
+func GetNamespaceLimitRanges(clientset kubernetes.Interface, namespace string) ([]v1.LimitRange, error) {
+	return limitRanges.get(clientset, namespace)
 }
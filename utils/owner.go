@@ -0,0 +1,244 @@
+// utils/owner.go
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Well-known labels and annotations identifying the tool that manages a workload.
+const (
+	helmReleaseNameAnnotation = "meta.helm.sh/release-name"
+	managedByLabel            = "app.kubernetes.io/managed-by"
+	instanceLabel             = "app.kubernetes.io/instance"
+	argoCDInstanceLabel       = "argocd.argoproj.io/instance"
+	argoCDTrackingAnnotation  = "argocd.argoproj.io/tracking-id"
+)
+
+// maxOwnerDepth bounds the walk up the ownerReferences chain.
+const maxOwnerDepth = 10
+
+var (
+	// teamLabels are the label keys looked up, in order, to find the team owning a workload.
+	teamLabels = []string{"team"}
+
+	ownerCache      = map[string]*metav1.ObjectMeta{}
+	namespaceLabels = map[string]map[string]string{}
+	ownerCacheMutex sync.Mutex
+)
+
+// SetTeamLabels sets the label keys looked up to find the team owning a workload.
+func SetTeamLabels(labels []string) {
+	teamLabels = labels
+}
+
+// ResolveOwner describes who owns a workload: its top-level controller (e.g. the CronJob of a Job),
+// the Helm release or Argo CD application managing it and the team found in its team labels.
+func ResolveOwner(clientset kubernetes.Interface, meta metav1.ObjectMeta) string {
+	var parts []string
+
+	kind, name, top := ResolveTopController(clientset, meta)
+	if kind != "" {
+		parts = append(parts, fmt.Sprintf("%s/%s", kind, name))
+	}
+
+	// Look at the workload first, then at its top-level controller
+	objects := []metav1.ObjectMeta{meta}
+	if top != nil {
+		objects = append(objects, *top)
+	}
+	if release := findHelmRelease(objects); release != "" {
+		parts = append(parts, "Helm: "+release)
+	}
+	if app := findArgoCDApplication(objects); app != "" {
+		parts = append(parts, "Argo CD: "+app)
+	}
+	if team := findTeam(clientset, meta.Namespace, objects); team != "" {
+		parts = append(parts, "Team: "+team)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ResolveTopController walks up the ownerReferences of an object and returns the kind and name of
+// its top-level controller, along with its metadata when it could be fetched.
+// An empty kind is returned for objects that are not owned by anything.
+func ResolveTopController(clientset kubernetes.Interface, meta metav1.ObjectMeta) (kind string, name string, top *metav1.ObjectMeta) {
+	chain, top := ResolveOwnerChain(clientset, meta)
+	if len(chain) == 0 {
+		return "", "", nil
+	}
+	last := chain[len(chain)-1]
+	return last.Kind, last.Name, top
+}
+
+// ResolveOwnerChain walks up the ownerReferences of an object and returns its owners, nearest first
+// (e.g. ReplicaSet then Deployment for a pod), along with the metadata of the last one when it could
+// be fetched. The walk stops at owners that cannot be fetched, e.g. custom resources.
+func ResolveOwnerChain(clientset kubernetes.Interface, meta metav1.ObjectMeta) (chain []metav1.OwnerReference, top *metav1.ObjectMeta) {
+	current := &meta
+	for depth := 0; depth < maxOwnerDepth; depth++ {
+		ref := ownerReferenceOf(current)
+		if ref == nil {
+			break
+		}
+		chain = append(chain, *ref)
+		owner, err := getOwnerObjectMeta(clientset, meta.Namespace, ref)
+		if err != nil {
+			Debug("Could not fetch owner", zap.String("kind", ref.Kind), zap.String("name", ref.Name), zap.Error(err))
+			return chain, nil
+		}
+		if owner == nil {
+			// Owner kind we do not know how to fetch, e.g. a custom resource
+			return chain, nil
+		}
+		current = owner
+		top = owner
+	}
+	return chain, top
+}
+
+// ownerReferenceOf returns the controller reference of an object, or its first owner reference.
+func ownerReferenceOf(meta *metav1.ObjectMeta) *metav1.OwnerReference {
+	if ref := metav1.GetControllerOf(meta); ref != nil {
+		return ref
+	}
+	if len(meta.OwnerReferences) > 0 {
+		return &meta.OwnerReferences[0]
+	}
+	return nil
+}
+
+// getOwnerObjectMeta fetches the metadata of an owner, caching it for the other workloads it owns.
+// A nil result without error means the owner kind is not supported.
+func getOwnerObjectMeta(clientset kubernetes.Interface, namespace string, ref *metav1.OwnerReference) (*metav1.ObjectMeta, error) {
+	key := fmt.Sprintf("%s/%s/%s", ref.Kind, namespace, ref.Name)
+	ownerCacheMutex.Lock()
+	cached, ok := ownerCache[key]
+	ownerCacheMutex.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	ctx := context.Background()
+	var owner *metav1.ObjectMeta
+	switch ref.Kind {
+	case "ReplicaSet":
+		obj, err := clientset.AppsV1().ReplicaSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		owner = &obj.ObjectMeta
+	case "Deployment":
+		obj, err := clientset.AppsV1().Deployments(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		owner = &obj.ObjectMeta
+	case "StatefulSet":
+		obj, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		owner = &obj.ObjectMeta
+	case "DaemonSet":
+		obj, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		owner = &obj.ObjectMeta
+	case "Job":
+		obj, err := clientset.BatchV1().Jobs(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		owner = &obj.ObjectMeta
+	case "CronJob":
+		obj, err := clientset.BatchV1().CronJobs(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		owner = &obj.ObjectMeta
+	default:
+		return nil, nil
+	}
+
+	ownerCacheMutex.Lock()
+	ownerCache[key] = owner
+	ownerCacheMutex.Unlock()
+	return owner, nil
+}
+
+// findHelmRelease returns the name of the Helm release managing any of the objects.
+func findHelmRelease(objects []metav1.ObjectMeta) string {
+	for _, meta := range objects {
+		if release := meta.Annotations[helmReleaseNameAnnotation]; release != "" {
+			return release
+		}
+		if strings.EqualFold(meta.Labels[managedByLabel], "Helm") && meta.Labels[instanceLabel] != "" {
+			return meta.Labels[instanceLabel]
+		}
+	}
+	return ""
+}
+
+// findArgoCDApplication returns the name of the Argo CD application managing any of the objects.
+func findArgoCDApplication(objects []metav1.ObjectMeta) string {
+	for _, meta := range objects {
+		// The tracking id has the form <application>:<group>/<kind>:<namespace>/<name>
+		if trackingID := meta.Annotations[argoCDTrackingAnnotation]; trackingID != "" {
+			return strings.SplitN(trackingID, ":", 2)[0]
+		}
+		if app := meta.Labels[argoCDInstanceLabel]; app != "" {
+			return app
+		}
+	}
+	return ""
+}
+
+// findTeam returns the value of the first team label found on the objects, falling back to the labels of their namespace.
+func findTeam(clientset kubernetes.Interface, namespace string, objects []metav1.ObjectMeta) string {
+	for _, label := range teamLabels {
+		for _, meta := range objects {
+			if team := meta.Labels[label]; team != "" {
+				return team
+			}
+		}
+	}
+
+	labels := GetNamespaceLabels(clientset, namespace)
+	for _, label := range teamLabels {
+		if team := labels[label]; team != "" {
+			return team
+		}
+	}
+	return ""
+}
+
+// GetNamespaceLabels fetches the labels of a namespace, caching them for the other workloads of the namespace.
+func GetNamespaceLabels(clientset kubernetes.Interface, namespace string) map[string]string {
+	ownerCacheMutex.Lock()
+	labels, ok := namespaceLabels[namespace]
+	ownerCacheMutex.Unlock()
+	if ok {
+		return labels
+	}
+
+	ns, err := clientset.CoreV1().Namespaces().Get(context.Background(), namespace, metav1.GetOptions{})
+	if err != nil {
+		Debug("Could not fetch namespace labels", zap.String("namespace", namespace), zap.Error(err))
+	} else {
+		labels = ns.Labels
+	}
+
+	ownerCacheMutex.Lock()
+	namespaceLabels[namespace] = labels
+	ownerCacheMutex.Unlock()
+	return labels
+}
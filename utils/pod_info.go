@@ -4,7 +4,7 @@ package utils
 
 import (
 	"fmt"
-	"strconv"
+	"math"
 	"strings"
 
 	"go.uber.org/zap"
@@ -22,114 +22,207 @@ func FormatNodeSelector(nodeSelector map[string]string) string {
 	return strings.Join(selectorPairs, ", ")
 }
 
-// FormatResourceQuantity converts a resource.Quantity to a string and ensures memory is in MiB.
-func FormatResourceQuantity(q resource.Quantity, resourceName v1.ResourceName) string {
-	if resourceName == v1.ResourceMemory {
-		// If the quantity is already in MiB, return it as is
-		if strings.HasSuffix(q.String(), "Mi") {
-			return q.String()
-		}
-		// Otherwise, convert to MiB
-		return strconv.FormatInt(q.ScaledValue(resource.Mega), 10) + "Mi"
-	}
-	return q.String()
+// bytesPerMiB is the number of bytes in a mebibyte.
+const bytesPerMiB = 1024 * 1024
+
+// CPUMillicores returns a CPU quantity as a number of millicores, the unit of the "(m)" report columns.
+func CPUMillicores(q resource.Quantity) int64 {
+	return q.MilliValue()
+}
+
+// MemoryMiB returns a memory quantity as a number of MiB rounded to two decimals, the unit of the "(MiB)" report columns.
+func MemoryMiB(q resource.Quantity) float64 {
+	return math.Round(float64(q.Value())/bytesPerMiB*100) / 100
+}
+
+// PodResources holds the resources of a pod template as the scheduler sees them, once the
+// LimitRange defaults of its namespace are applied to every container. The CPU and memory
+// requests and limits are the effective pod values: the largest of the init phase and of the
+// app containers plus sidecars, plus the pod overhead.
+type PodResources struct {
+	CPURequests    resource.Quantity
+	MemoryRequests resource.Quantity
+	CPULimits      resource.Quantity
+	MemoryLimits   resource.Quantity
+	// InitCPURequests and InitMemoryRequests are the peak requests while the init containers run,
+	// including the sidecars started before them.
+	InitCPURequests    resource.Quantity
+	InitMemoryRequests resource.Quantity
+	// SidecarCPURequests and SidecarMemoryRequests are the requests of the native sidecars,
+	// i.e. the init containers with restartPolicy Always, which run next to the app containers.
+	SidecarCPURequests    resource.Quantity
+	SidecarMemoryRequests resource.Quantity
+	// OverheadCPU and OverheadMemory are the pod overhead set by the RuntimeClass.
+	OverheadCPU    resource.Quantity
+	OverheadMemory resource.Quantity
+	QoSClass       string
+	// DefaultsApplied is true when a LimitRange default filled in a missing container request or limit.
+	DefaultsApplied bool
+	// LimitRangeViolations lists the LimitRange Min, Max and MaxLimitRequestRatio constraints the pod would violate.
+	LimitRangeViolations []string
+}
+
+// CPUDiff returns the difference between the CPU limits and requests.
+func (r PodResources) CPUDiff() resource.Quantity {
+	diff := r.CPULimits.DeepCopy()
+	diff.Sub(r.CPURequests)
+	return diff
 }
 
-// ExtractResources takes a PodSpec and returns formatted strings of CPU and memory requests and limits.
-func ExtractResources(clientset *kubernetes.Clientset, podSpec v1.PodSpec, namespace string) (
-	cpuRequests string,
-	memoryRequests string,
-	cpuLimits string,
-	memoryLimits string,
-	cpuDiff string,
-	memoryDiff string,
-	memoryReadiness bool,
-	qosClass string) {
-	var cpuReqTotal, memReqTotal, cpuLimitTotal, memLimitTotal resource.Quantity
-	for _, container := range podSpec.Containers {
-		if cpu, ok := container.Resources.Requests[v1.ResourceCPU]; ok {
-			cpuReqTotal.Add(cpu)
-		} else {
+// MemoryDiff returns the difference between the memory limits and requests.
+func (r PodResources) MemoryDiff() resource.Quantity {
+	diff := r.MemoryLimits.DeepCopy()
+	diff.Sub(r.MemoryRequests)
+	return diff
+}
+
+// MemoryReadiness reports whether the memory diff is more than twice the memory request.
+func (r PodResources) MemoryReadiness() bool {
+	memoryDiff := r.MemoryDiff()
+	return memoryDiff.MilliValue() > r.MemoryRequests.MilliValue()*2
+}
+
+// ExtractResources takes a PodSpec and returns its CPU and memory requests and limits, its QoS class
+// and how the LimitRanges of its namespace apply to it.
+func ExtractResources(clientset kubernetes.Interface, podSpec v1.PodSpec, namespace string) PodResources {
+	namespaceLimitRanges, err := GetNamespaceLimitRanges(clientset, namespace)
+	if err != nil {
+		Debug("No LimitRange defaults applied for namespace", zap.String("namespace", namespace), zap.Error(err))
+	}
+	containers, containersDefaulted := applyLimitRangeDefaults(podSpec.Containers, namespaceLimitRanges)
+	initContainers, initContainersDefaulted := applyLimitRangeDefaults(podSpec.InitContainers, namespaceLimitRanges)
+
+	var resources PodResources
+	resources.DefaultsApplied = containersDefaulted || initContainersDefaulted
+	for _, container := range append(append([]v1.Container{}, initContainers...), containers...) {
+		if _, ok := container.Resources.Requests[v1.ResourceCPU]; !ok {
 			Debug("No CPU request specified for container", zap.String("containerName", container.Name))
 		}
-		if memory, ok := container.Resources.Requests[v1.ResourceMemory]; ok {
-			memReqTotal.Add(memory)
-		} else {
+		if _, ok := container.Resources.Requests[v1.ResourceMemory]; !ok {
 			Debug("No memory request specified for container", zap.String("containerName", container.Name))
 		}
-		if cpu, ok := container.Resources.Limits[v1.ResourceCPU]; ok {
-			cpuLimitTotal.Add(cpu)
-		} else {
+		if _, ok := container.Resources.Limits[v1.ResourceCPU]; !ok {
 			Debug("No CPU limit specified for container", zap.String("containerName", container.Name))
 		}
-		if memory, ok := container.Resources.Limits[v1.ResourceMemory]; ok {
-			memLimitTotal.Add(memory)
-		} else {
+		if _, ok := container.Resources.Limits[v1.ResourceMemory]; !ok {
 			Debug("No memory limit specified for container", zap.String("containerName", container.Name))
 		}
 	}
 
-	// After accumulating requests and limits, check if they are zero and set defaults if necessary
-	if cpuReqTotal.IsZero() {
-		Debug("Total CPU request is zero, getting default CPU request for namespace", zap.String("namespace", namespace))
-		cpuReqTotal = GetNamespaceDefaultCPURequests(clientset, namespace)
+	podRequests, initRequests, sidecarRequests := effectivePodResources(containers, initContainers, podSpec.Overhead, func(c v1.Container) v1.ResourceList { return c.Resources.Requests })
+	podLimits, _, _ := effectivePodResources(containers, initContainers, podSpec.Overhead, func(c v1.Container) v1.ResourceList { return c.Resources.Limits })
+	resources.CPURequests = quantityOf(podRequests, v1.ResourceCPU)
+	resources.MemoryRequests = quantityOf(podRequests, v1.ResourceMemory)
+	resources.CPULimits = quantityOf(podLimits, v1.ResourceCPU)
+	resources.MemoryLimits = quantityOf(podLimits, v1.ResourceMemory)
+	resources.InitCPURequests = quantityOf(initRequests, v1.ResourceCPU)
+	resources.InitMemoryRequests = quantityOf(initRequests, v1.ResourceMemory)
+	resources.SidecarCPURequests = quantityOf(sidecarRequests, v1.ResourceCPU)
+	resources.SidecarMemoryRequests = quantityOf(sidecarRequests, v1.ResourceMemory)
+	resources.OverheadCPU = quantityOf(podSpec.Overhead, v1.ResourceCPU)
+	resources.OverheadMemory = quantityOf(podSpec.Overhead, v1.ResourceMemory)
+
+	allContainers := append(append([]v1.Container{}, initContainers...), containers...)
+	resources.LimitRangeViolations = validateLimitRanges(namespaceLimitRanges, allContainers, podRequests, podLimits)
+	resources.QoSClass = qosClassOf(allContainers)
+
+	Debug(
+		"Extracted resources",
+		zap.String("CPU Requests", resources.CPURequests.String()),
+		zap.String("Memory Requests", resources.MemoryRequests.String()),
+		zap.String("CPU Limits", resources.CPULimits.String()),
+		zap.String("Memory Limits", resources.MemoryLimits.String()),
+		zap.Bool("LimitRange defaults applied", resources.DefaultsApplied),
+		zap.Strings("LimitRange violations", resources.LimitRangeViolations),
+	)
+	if resources.MemoryReadiness() {
+		Debug("Memory diff is more then twice the request!")
+	}
+	return resources
+}
+
+// RunningContainers returns the containers that keep running once a pod started, native sidecars then
+// app containers, with the requests and limits they get after the LimitRange defaults of the namespace.
+func RunningContainers(clientset kubernetes.Interface, podSpec v1.PodSpec, namespace string) []v1.Container {
+	namespaceLimitRanges, err := GetNamespaceLimitRanges(clientset, namespace)
+	if err != nil {
+		Debug("No LimitRange defaults applied for namespace", zap.String("namespace", namespace), zap.Error(err))
 	}
-	if memReqTotal.IsZero() {
-		Debug("Total memory request is zero, getting default memory request for namespace", zap.String("namespace", namespace))
-		memReqTotal = GetNamespaceDefaultMemoryRequests(clientset, namespace)
+	var running []v1.Container
+	for _, container := range podSpec.InitContainers {
+		if isSidecar(container) {
+			running = append(running, container)
+		}
 	}
-	if cpuLimitTotal.IsZero() {
-		Debug("Total CPU limit is zero, getting default CPU limit for namespace", zap.String("namespace", namespace))
-		cpuLimitTotal = GetNamespaceDefaultCPULimits(clientset, namespace)
+	running = append(running, podSpec.Containers...)
+	running, _ = applyLimitRangeDefaults(running, namespaceLimitRanges)
+	return running
+}
+
+// effectivePodResources computes the requests (or limits) of a pod the way the scheduler does:
+// the app containers and sidecars run together, each init container runs next to the sidecars
+// started before it, the pod needs the largest of both phases, plus the pod overhead.
+// It also returns the peak of the init phase and the sum of the sidecars.
+func effectivePodResources(containers []v1.Container, initContainers []v1.Container, overhead v1.ResourceList, resourcesOf func(v1.Container) v1.ResourceList) (pod v1.ResourceList, initPeak v1.ResourceList, sidecars v1.ResourceList) {
+	pod, initPeak, sidecars = v1.ResourceList{}, v1.ResourceList{}, v1.ResourceList{}
+	for _, container := range containers {
+		addResourceList(pod, resourcesOf(container))
 	}
-	if memLimitTotal.IsZero() {
-		Debug("Total memory limit is zero, getting default memory limit for namespace", zap.String("namespace", namespace))
-		memLimitTotal = GetNamespaceDefaultMemoryLimits(clientset, namespace)
+
+	for _, container := range initContainers {
+		running := v1.ResourceList{}
+		addResourceList(running, sidecars)
+		addResourceList(running, resourcesOf(container))
+		if isSidecar(container) {
+			addResourceList(pod, resourcesOf(container))
+			addResourceList(sidecars, resourcesOf(container))
+		}
+		maxResourceList(initPeak, running)
 	}
 
-	cpuRequests = strconv.FormatInt(cpuReqTotal.MilliValue(), 10) + "m"
-	memoryRequests = FormatResourceQuantity(memReqTotal, v1.ResourceMemory)
-	cpuLimits = strconv.FormatInt(cpuLimitTotal.MilliValue(), 10) + "m"
-	memoryLimits = FormatResourceQuantity(memLimitTotal, v1.ResourceMemory)
-	Debug(
-		"Extracted resources",
-		zap.String("CPU Requests", cpuRequests),
-		zap.String("Memory Requests", memoryRequests),
-		zap.String("CPU Limits", cpuLimits),
-		zap.String("Memory Limits", memoryLimits),
-	)
-	Debug(
-		"Subtracting",
-		zap.String("CPU limit total", cpuLimitTotal.String()),
-		zap.String("CPU request total", cpuReqTotal.String()),
-	)
-	cpuLimitTotal.Sub(cpuReqTotal)
-	cpuDiff = strconv.FormatInt(cpuLimitTotal.MilliValue(), 10) + "m"
+	maxResourceList(pod, initPeak)
+	addResourceList(pod, overhead)
+	return pod, initPeak, sidecars
+}
 
-	Debug(
-		"Subtracting",
-		zap.String("Memory limit total", memLimitTotal.String()),
-		zap.String("Memory request total", memReqTotal.String()),
-	)
-	memLimitTotal.Sub(memReqTotal)
-	memoryDiff = FormatResourceQuantity(memLimitTotal, v1.ResourceMemory)
+// isSidecar reports whether an init container is a native sidecar, i.e. has restartPolicy Always.
+func isSidecar(container v1.Container) bool {
+	return container.RestartPolicy != nil && *container.RestartPolicy == v1.ContainerRestartPolicyAlways
+}
 
-	Debug(
-		"Extracted diff",
-		zap.String("CPU Diff", cpuDiff),
-		zap.String("Memory Diff", memoryDiff),
-	)
+// addResourceList adds every quantity of src to dst.
+func addResourceList(dst v1.ResourceList, src v1.ResourceList) {
+	for name, quantity := range src {
+		value := dst[name]
+		value.Add(quantity)
+		dst[name] = value
+	}
+}
 
-	memoryReadiness = (memLimitTotal.MilliValue() > memReqTotal.MilliValue()*2)
-	if memoryReadiness {
-		Debug("Memory diff is more then twice the request!")
+// maxResourceList raises every quantity of dst to the matching quantity of src when src is bigger.
+func maxResourceList(dst v1.ResourceList, src v1.ResourceList) {
+	for name, quantity := range src {
+		if value, ok := dst[name]; !ok || quantity.Cmp(value) > 0 {
+			dst[name] = quantity.DeepCopy()
+		}
 	}
-	// Determine QoS class based on the requests and limits
+}
+
+// quantityOf returns the quantity of a resource in a list, or zero when it is not set.
+func quantityOf(list v1.ResourceList, name v1.ResourceName) resource.Quantity {
+	if quantity, ok := list[name]; ok {
+		return quantity.DeepCopy()
+	}
+	return resource.Quantity{}
+}
+
+// qosClassOf determines the QoS class of a pod from the requests and limits of its containers.
+func qosClassOf(containers []v1.Container) string {
 	guaranteed := true
 	burstable := false
 
 	// Iterate over all containers to determine the QoS class
-	for _, container := range podSpec.Containers {
+	for _, container := range containers {
 		containerRequests := container.Resources.Requests
 		containerLimits := container.Resources.Limits
 
@@ -183,86 +276,38 @@ func ExtractResources(clientset *kubernetes.Clientset, podSpec v1.PodSpec, names
 
 	// Assign the QoS class based on the flags
 	if guaranteed {
-		qosClass = "Guaranteed"
+		return "Guaranteed"
 	} else if burstable {
-		qosClass = "Burstable"
-	} else {
-		qosClass = "BestEffort"
+		return "Burstable"
 	}
-
-	// Return all the calculated resource information along with the QoS class
-	return
+	return "BestEffort"
 }
 
-// ExtractImageVersions takes a PodSpec and returns a string containing image versions (tags).
+// ExtractImageVersions takes a PodSpec and returns a string containing image versions (tags, or digests
+// for images only pinned by digest) of its app containers followed by its init containers and sidecars.
 func ExtractImageVersions(podSpec v1.PodSpec) string {
 	var imageVersions []string
-	for _, container := range podSpec.Containers {
-		image := container.Image
-		parts := strings.Split(image, ":")
-		var version string
-		if len(parts) > 1 {
-			version = parts[1]
-		} else {
-			version = "latest" // Default tag if none is specified
+	for _, image := range ExtractContainerImages(podSpec) {
+		if image.Err != nil {
+			Debug("Failed to parse image reference", zap.String("containerName", image.Container), zap.Error(image.Err))
+			imageVersions = append(imageVersions, image.Image)
+			continue
 		}
-		imageVersions = append(imageVersions, version)
+		imageVersions = append(imageVersions, image.Reference.Version())
 	}
 	return strings.Join(imageVersions, ", ")
 }
 
-// DetermineQoSClass takes a PodSpec and returns its QoS class as a string.
-func DetermineQoSClass(podSpec v1.PodSpec) string {
-	guaranteed := true
-	burstable := false
-	for _, container := range podSpec.Containers {
-		requests := container.Resources.Requests
-		limits := container.Resources.Limits
-		if len(requests) == 0 && len(limits) == 0 {
-			guaranteed = false
-			continue
-		}
-
-		if _, ok := requests[v1.ResourceCPU]; ok {
-			burstable = true
-		}
-		if _, ok := requests[v1.ResourceMemory]; ok {
-			burstable = true
-		}
-
-		if _, ok := limits[v1.ResourceCPU]; !ok {
-			guaranteed = false
-		}
-		if _, ok := limits[v1.ResourceMemory]; !ok {
-			guaranteed = false
-		}
-
-		if cpuLimit, ok := limits[v1.ResourceCPU]; ok {
-			if cpuRequest, ok := requests[v1.ResourceCPU]; ok {
-				if cpuLimit.Cmp(cpuRequest) != 0 {
-					guaranteed = false
-				}
-			} else {
-				guaranteed = false
-			}
-		}
-
-		if memLimit, ok := limits[v1.ResourceMemory]; ok {
-			if memRequest, ok := requests[v1.ResourceMemory]; ok {
-				if memLimit.Cmp(memRequest) != 0 {
-					guaranteed = false
-				}
-			} else {
-				guaranteed = false
-			}
-		}
-	}
+// MultiplyQuantity returns a quantity multiplied by a count, e.g. the requests of a pod by its replicas.
+func MultiplyQuantity(q resource.Quantity, count int32) resource.Quantity {
+	return *resource.NewMilliQuantity(q.MilliValue()*int64(count), q.Format)
+}
 
-	if guaranteed {
-		return "Guaranteed"
-	}
-	if burstable {
-		return "Burstable"
-	}
-	return "BestEffort"
+// PodRequestsAndLimits returns the effective requests and limits of a scheduled pod, computed like
+// the scheduler does. Unlike ExtractResources no LimitRange default is applied, the pod already went
+// through admission.
+func PodRequestsAndLimits(podSpec v1.PodSpec) (requests v1.ResourceList, limits v1.ResourceList) {
+	requests, _, _ = effectivePodResources(podSpec.Containers, podSpec.InitContainers, podSpec.Overhead, func(c v1.Container) v1.ResourceList { return c.Resources.Requests })
+	limits, _, _ = effectivePodResources(podSpec.Containers, podSpec.InitContainers, podSpec.Overhead, func(c v1.Container) v1.ResourceList { return c.Resources.Limits })
+	return requests, limits
 }
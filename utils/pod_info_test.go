@@ -0,0 +1,125 @@
+// utils/pod_info_test.go
+
+package utils
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func requestingContainer(name string, cpu string, memory string) v1.Container {
+	return v1.Container{Name: name, Resources: v1.ResourceRequirements{Requests: resourceList(cpu, memory)}}
+}
+
+func sidecarContainer(name string, cpu string, memory string) v1.Container {
+	container := requestingContainer(name, cpu, memory)
+	always := v1.ContainerRestartPolicyAlways
+	container.RestartPolicy = &always
+	return container
+}
+
+func TestEffectivePodResources(t *testing.T) {
+	tests := []struct {
+		name           string
+		containers     []v1.Container
+		initContainers []v1.Container
+		overhead       v1.ResourceList
+		wantPod        v1.ResourceList
+		wantInitPeak   v1.ResourceList
+		wantSidecars   v1.ResourceList
+	}{
+		{
+			name:         "app containers are summed",
+			containers:   []v1.Container{requestingContainer("app", "250m", "256Mi"), requestingContainer("proxy", "50m", "64Mi")},
+			wantPod:      resourceList("300m", "320Mi"),
+			wantInitPeak: v1.ResourceList{},
+			wantSidecars: v1.ResourceList{},
+		},
+		{
+			name:           "the init peak wins over the app containers when bigger",
+			containers:     []v1.Container{requestingContainer("app", "250m", "256Mi")},
+			initContainers: []v1.Container{requestingContainer("migrate", "1", "128Mi"), requestingContainer("warmup", "100m", "1Gi")},
+			// init containers run one at a time: the peak is per resource, not their sum
+			wantPod:      resourceList("1", "1Gi"),
+			wantInitPeak: resourceList("1", "1Gi"),
+			wantSidecars: v1.ResourceList{},
+		},
+		{
+			name:           "the app containers win over a smaller init peak",
+			containers:     []v1.Container{requestingContainer("app", "500m", "512Mi"), requestingContainer("worker", "500m", "512Mi")},
+			initContainers: []v1.Container{requestingContainer("migrate", "800m", "256Mi")},
+			wantPod:        resourceList("1", "1Gi"),
+			wantInitPeak:   resourceList("800m", "256Mi"),
+			wantSidecars:   v1.ResourceList{},
+		},
+		{
+			name:       "sidecars run next to the app containers and the later init containers",
+			containers: []v1.Container{requestingContainer("app", "250m", "256Mi")},
+			initContainers: []v1.Container{
+				requestingContainer("before", "400m", "64Mi"),
+				sidecarContainer("mesh", "100m", "128Mi"),
+				requestingContainer("after", "400m", "64Mi"),
+			},
+			// app phase: 250m + 100m, 256Mi + 128Mi; init peak: 100m + 400m, 128Mi + 64Mi
+			wantPod:      resourceList("500m", "384Mi"),
+			wantInitPeak: resourceList("500m", "192Mi"),
+			wantSidecars: resourceList("100m", "128Mi"),
+		},
+		{
+			name:         "overhead is added on top",
+			containers:   []v1.Container{requestingContainer("app", "250m", "256Mi")},
+			overhead:     resourceList("100m", "64Mi"),
+			wantPod:      resourceList("350m", "320Mi"),
+			wantInitPeak: v1.ResourceList{},
+			wantSidecars: v1.ResourceList{},
+		},
+		{
+			name:           "overhead is added to the init peak too",
+			containers:     []v1.Container{requestingContainer("app", "100m", "")},
+			initContainers: []v1.Container{requestingContainer("migrate", "1", "")},
+			overhead:       resourceList("100m", ""),
+			wantPod:        resourceList("1100m", ""),
+			wantInitPeak:   resourceList("1", ""),
+			wantSidecars:   v1.ResourceList{},
+		},
+		{
+			name:         "unset resources stay unset",
+			containers:   []v1.Container{requestingContainer("app", "100m", ""), {Name: "besteffort"}},
+			wantPod:      resourceList("100m", ""),
+			wantInitPeak: v1.ResourceList{},
+			wantSidecars: v1.ResourceList{},
+		},
+	}
+	requestsOf := func(c v1.Container) v1.ResourceList { return c.Resources.Requests }
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod, initPeak, sidecars := effectivePodResources(test.containers, test.initContainers, test.overhead, requestsOf)
+			if !sameResources(pod, test.wantPod) {
+				t.Errorf("pod requests are %v, want %v", pod, test.wantPod)
+			}
+			if !sameResources(initPeak, test.wantInitPeak) {
+				t.Errorf("init peak is %v, want %v", initPeak, test.wantInitPeak)
+			}
+			if !sameResources(sidecars, test.wantSidecars) {
+				t.Errorf("sidecars are %v, want %v", sidecars, test.wantSidecars)
+			}
+		})
+	}
+}
+
+func TestPodRequestsAndLimits(t *testing.T) {
+	app := requestingContainer("app", "250m", "256Mi")
+	app.Resources.Limits = resourceList("500m", "512Mi")
+	mesh := sidecarContainer("mesh", "100m", "128Mi")
+	mesh.Resources.Limits = resourceList("200m", "128Mi")
+	podSpec := v1.PodSpec{Containers: []v1.Container{app}, InitContainers: []v1.Container{mesh}}
+
+	requests, limits := PodRequestsAndLimits(podSpec)
+	if want := resourceList("350m", "384Mi"); !sameResources(requests, want) {
+		t.Errorf("requests are %v, want %v", requests, want)
+	}
+	if want := resourceList("700m", "640Mi"); !sameResources(limits, want) {
+		t.Errorf("limits are %v, want %v", limits, want)
+	}
+}
@@ -0,0 +1,107 @@
+// utils/pricing.go
+
+package utils
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// HoursPerMonth is the average number of hours in a month, used to turn hourly rates into monthly costs.
+const HoursPerMonth = 730
+
+// Rates are the hourly prices of a vCPU and of a GiB of memory.
+type Rates struct {
+	CPUHourly       float64 `json:"cpuHourly"`
+	MemoryGiBHourly float64 `json:"memoryGiBHourly"`
+}
+
+// PriceTable is the price table given with --pricing. Namespace and instance type rates override the
+// default rates; a rate they leave unset (zero) falls back to the default one.
+type PriceTable struct {
+	Currency string `json:"currency"`
+	Rates
+	// Namespaces are the rates charged back to the workloads of a namespace.
+	Namespaces map[string]Rates `json:"namespaces"`
+	// InstanceTypes are the rates of the nodes of an instance type (node.kubernetes.io/instance-type).
+	InstanceTypes map[string]Rates `json:"instanceTypes"`
+}
+
+// priceTable is the price table of this run, nil unless --pricing is set.
+var priceTable *PriceTable
+
+// LoadPriceTable reads a YAML (or JSON) price table from a file.
+func LoadPriceTable(path string) (*PriceTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var prices PriceTable
+	if err := yaml.UnmarshalStrict(data, &prices); err != nil {
+		return nil, fmt.Errorf("invalid price table %s: %w", path, err)
+	}
+	if prices.Rates.negative() {
+		return nil, fmt.Errorf("invalid price table %s: rates must not be negative", path)
+	}
+	for namespace, rates := range prices.Namespaces {
+		if rates.negative() {
+			return nil, fmt.Errorf("invalid price table %s: rates of namespace %s must not be negative", path, namespace)
+		}
+	}
+	for instanceType, rates := range prices.InstanceTypes {
+		if rates.negative() {
+			return nil, fmt.Errorf("invalid price table %s: rates of instance type %s must not be negative", path, instanceType)
+		}
+	}
+	if prices.Currency == "" {
+		prices.Currency = "USD"
+	}
+	return &prices, nil
+}
+
+// SetPriceTable sets the price table used to compute the cost columns.
+func SetPriceTable(prices *PriceTable) {
+	priceTable = prices
+}
+
+// GetPriceTable returns the price table of this run, or nil when costs are not reported.
+func GetPriceTable() *PriceTable {
+	return priceTable
+}
+
+// withDefaults fills the rates left unset with the default rates of the table.
+func (p *PriceTable) withDefaults(rates Rates) Rates {
+	if rates.CPUHourly == 0 {
+		rates.CPUHourly = p.CPUHourly
+	}
+	if rates.MemoryGiBHourly == 0 {
+		rates.MemoryGiBHourly = p.MemoryGiBHourly
+	}
+	return rates
+}
+
+// NamespaceRates returns the rates charged to the workloads of a namespace.
+func (p *PriceTable) NamespaceRates(namespace string) Rates {
+	return p.withDefaults(p.Namespaces[namespace])
+}
+
+// InstanceTypeRates returns the rates of the nodes of an instance type.
+func (p *PriceTable) InstanceTypeRates(instanceType string) Rates {
+	return p.withDefaults(p.InstanceTypes[instanceType])
+}
+
+// negative reports whether a rate is negative.
+func (r Rates) negative() bool {
+	return r.CPUHourly < 0 || r.MemoryGiBHourly < 0
+}
+
+// MonthlyCost returns the monthly cost of the given CPU and memory, rounded to the cent.
+func (r Rates) MonthlyCost(cpu resource.Quantity, memory resource.Quantity) float64 {
+	vCPUs := float64(cpu.MilliValue()) / 1000
+	gibs := float64(memory.Value()) / (1024 * 1024 * 1024)
+	return math.Round((vCPUs*r.CPUHourly+gibs*r.MemoryGiBHourly)*HoursPerMonth*100) / 100
+}
@@ -0,0 +1,60 @@
+// utils/pricing_test.go
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePriceTable(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("writing price table: %v", err)
+	}
+	return path
+}
+
+func TestLoadPriceTable(t *testing.T) {
+	prices, err := LoadPriceTable(writePriceTable(t, `
+cpuHourly: 0.04
+memoryGiBHourly: 0.005
+namespaces:
+  batch: {cpuHourly: 0.01}
+instanceTypes:
+  m5.large: {cpuHourly: 0.048, memoryGiBHourly: 0.006}
+`))
+	if err != nil {
+		t.Fatalf("LoadPriceTable: %v", err)
+	}
+	if prices.Currency != "USD" {
+		t.Errorf("currency is %q, want the default USD", prices.Currency)
+	}
+	if rates := prices.NamespaceRates("batch"); rates.CPUHourly != 0.01 || rates.MemoryGiBHourly != 0.005 {
+		t.Errorf("rates of namespace batch are %+v, want its CPU rate and the default memory rate", rates)
+	}
+	if rates := prices.InstanceTypeRates("m5.large"); rates.CPUHourly != 0.048 || rates.MemoryGiBHourly != 0.006 {
+		t.Errorf("rates of instance type m5.large are %+v", rates)
+	}
+}
+
+func TestLoadPriceTableRejectsNegativeRates(t *testing.T) {
+	for content, want := range map[string]string{
+		"cpuHourly: -0.04": "rates must not be negative",
+		"namespaces:\n  batch: {memoryGiBHourly: -0.005}":        "namespace batch",
+		"instanceTypes:\n  m5.large: {cpuHourly: -0.048}":        "instance type m5.large",
+		"cpuHourly: 0.04\nnamespaces:\n  web: {cpuHourly: -1}\n": "namespace web",
+	} {
+		_, err := LoadPriceTable(writePriceTable(t, content))
+		if err == nil {
+			t.Errorf("LoadPriceTable accepted %q", content)
+			continue
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q for %q does not mention %q", err, content, want)
+		}
+	}
+}
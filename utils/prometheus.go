@@ -0,0 +1,112 @@
+// utils/prometheus.go
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PrometheusClient queries the HTTP API of Prometheus, or of any backend compatible with it
+// (Thanos, Mimir, VictoriaMetrics, ...).
+type PrometheusClient struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// PrometheusSample is a single element of an instant vector: the labels of a series and its value.
+type PrometheusSample struct {
+	Metric map[string]string
+	Value  float64
+}
+
+// prometheusResponse is the envelope of every response of the Prometheus HTTP API.
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// NewPrometheusClient returns a client for the Prometheus HTTP API served at the given URL.
+func NewPrometheusClient(prometheusURL string) (*PrometheusClient, error) {
+	parsed, err := url.Parse(prometheusURL)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("invalid Prometheus URL %q: must start with http:// or https://", prometheusURL)
+	}
+	return &PrometheusClient{
+		URL:        strings.TrimSuffix(prometheusURL, "/"),
+		HTTPClient: &http.Client{Timeout: 2 * time.Minute},
+	}, nil
+}
+
+// Query evaluates a PromQL expression at the current time and returns the resulting instant vector.
+func (c *PrometheusClient) Query(ctx context.Context, query string) ([]PrometheusSample, error) {
+	Debug("Querying Prometheus", zap.String("query", query))
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL+"/api/v1/query", strings.NewReader(url.Values{"query": {query}}.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result prometheusResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("unexpected response from Prometheus (HTTP %d): %w", response.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed (HTTP %d): %s: %s", response.StatusCode, result.ErrorType, result.Error)
+	}
+	if result.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("prometheus query returned a %s, expected a vector", result.Data.ResultType)
+	}
+
+	samples := make([]PrometheusSample, 0, len(result.Data.Result))
+	for _, series := range result.Data.Result {
+		if len(series.Value) != 2 {
+			return nil, fmt.Errorf("prometheus query returned a malformed sample: %v", series.Value)
+		}
+		text, ok := series.Value[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("prometheus query returned a malformed sample value: %v", series.Value[1])
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, PrometheusSample{Metric: series.Metric, Value: value})
+	}
+	return samples, nil
+}
+
+// PrometheusDuration formats a duration the way PromQL range selectors expect it, e.g. "604800s".
+func PrometheusDuration(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(d.Seconds()))
+}
@@ -0,0 +1,103 @@
+// utils/prometheus_test.go
+
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// prometheusServer stands in for the query API of Prometheus, answering every query with the given
+// status code and body.
+func prometheusServer(t *testing.T, status int, body string) *PrometheusClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/query" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if query := r.FormValue("query"); query != "up" {
+			t.Errorf("query is %q, want %q", query, "up")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewPrometheusClient(server.URL + "/")
+	if err != nil {
+		t.Fatalf("NewPrometheusClient: %v", err)
+	}
+	return client
+}
+
+func TestPrometheusQuery(t *testing.T) {
+	client := prometheusServer(t, http.StatusOK, `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"namespace":"shop","pod":"web-7c9d6-x7k2p","container":"app"},"value":[1700000000.123,"0.25"]},
+		{"metric":{"namespace":"shop","pod":"web-7c9d6-b4n8q","container":"app"},"value":[1700000000.123,"1e-3"]}
+	]}}`)
+
+	samples, err := client.Query(context.Background(), "up")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("got %d samples, want 2", len(samples))
+	}
+	if samples[0].Metric["pod"] != "web-7c9d6-x7k2p" || samples[0].Value != 0.25 {
+		t.Errorf("first sample is %+v", samples[0])
+	}
+	if samples[1].Value != 0.001 {
+		t.Errorf("second sample value is %v, want 0.001", samples[1].Value)
+	}
+}
+
+func TestPrometheusQueryEmptyResult(t *testing.T) {
+	client := prometheusServer(t, http.StatusOK, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+
+	samples, err := client.Query(context.Background(), "up")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(samples) != 0 {
+		t.Errorf("got %d samples, want none", len(samples))
+	}
+}
+
+func TestPrometheusQueryError(t *testing.T) {
+	client := prometheusServer(t, http.StatusBadRequest, `{"status":"error","errorType":"bad_data","error":"invalid parameter \"query\": parse error"}`)
+
+	_, err := client.Query(context.Background(), "up")
+	if err == nil {
+		t.Fatal("Query succeeded on an error response")
+	}
+	for _, want := range []string{"HTTP 400", "bad_data", "parse error"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestPrometheusQueryUnexpectedResponses(t *testing.T) {
+	for name, body := range map[string]string{
+		"not JSON":        `<html>502 Bad Gateway</html>`,
+		"matrix":          `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+		"malformed value": `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,0.25]}]}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := prometheusServer(t, http.StatusOK, body)
+			if _, err := client.Query(context.Background(), "up"); err == nil {
+				t.Error("Query succeeded on an unexpected response")
+			}
+		})
+	}
+}
+
+func TestNewPrometheusClientRejectsOtherSchemes(t *testing.T) {
+	if _, err := NewPrometheusClient("prometheus:9090"); err == nil {
+		t.Error("NewPrometheusClient accepted a URL without an http or https scheme")
+	}
+}
@@ -0,0 +1,97 @@
+// utils/report_writer.go
+
+package utils
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Supported report output formats.
+const (
+	OutputXLSX   = "xlsx"
+	OutputCSV    = "csv"
+	OutputJSON   = "json"
+	OutputYAML   = "yaml"
+	OutputNDJSON = "ndjson"
+)
+
+// OutputFormats lists every format accepted by NewReportWriter.
+var OutputFormats = []string{OutputXLSX, OutputCSV, OutputJSON, OutputYAML, OutputNDJSON}
+
+// reportBaseName is the file name (without extension) every report is written to.
+const reportBaseName = "k8s_report"
+
+// ReportWriter is the sink handlers feed their rows into. A report is made of
+// named sections (one per resource kind), each with its own column headers.
+//
+// Records hold typed cells so that every format can keep numbers as numbers: strings,
+// integers (counts, CPU in millicores), float64 (memory in MiB), booleans, or nil for an
+// empty cell. The unit of a numeric column is part of its header, e.g. "CPU Requests (m)".
+type ReportWriter interface {
+	// AddSection registers a section and its column headers. Adding an existing section is a no-op.
+	AddSection(section string, headers []string) error
+	// WriteRow appends a record to a section previously registered with AddSection.
+	WriteRow(section string, record []interface{}) error
+	// Close flushes the report to its destination.
+	Close() error
+}
+
+var (
+	reportWriter     ReportWriter
+	reportWriterErr  error
+	reportWriterOnce sync.Once
+)
+
+// NewReportWriter creates a report writer for the given output format.
+func NewReportWriter(format string) (ReportWriter, error) {
+	switch format {
+	case OutputXLSX:
+		return newExcelReportWriter(reportBaseName + ".xlsx")
+	case OutputCSV:
+		return newCSVReportWriter(reportBaseName), nil
+	case OutputJSON:
+		return newDocumentReportWriter(reportBaseName+".json", marshalJSONDocument), nil
+	case OutputYAML:
+		return newDocumentReportWriter(reportBaseName+".yaml", marshalYAMLDocument), nil
+	case OutputNDJSON:
+		return newNDJSONReportWriter(reportBaseName + ".ndjson")
+	}
+	return nil, fmt.Errorf("unsupported output format %q", format)
+}
+
+// OpenReportWriter returns the report writer shared by all commands of this run,
+// creating it for the given format on first use.
+func OpenReportWriter(format string) (ReportWriter, error) {
+	reportWriterOnce.Do(func() {
+		reportWriter, reportWriterErr = NewReportWriter(format)
+	})
+	if reportWriterErr != nil {
+		Error("Failed to open report writer", zap.String("format", format), zap.Error(reportWriterErr))
+		return nil, reportWriterErr
+	}
+	return reportWriter, nil
+}
+
+// CloseReportWriter closes the shared report writer, if one was opened.
+func CloseReportWriter() error {
+	if reportWriter == nil {
+		return nil
+	}
+	return reportWriter.Close()
+}
+
+// recordToObject maps a record to its headers, for the formats that write rows as objects.
+func recordToObject(headers []string, record []interface{}) map[string]interface{} {
+	object := make(map[string]interface{}, len(headers))
+	for i, header := range headers {
+		if i < len(record) {
+			object[header] = record[i]
+		} else {
+			object[header] = nil
+		}
+	}
+	return object
+}
@@ -0,0 +1,131 @@
+// utils/rules.go
+
+package utils
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/google/cel-go/cel"
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+)
+
+// Variables the rule expressions are evaluated with.
+const (
+	// ObjectVariable is the Kubernetes object of the workload, e.g. object.spec.template.spec.
+	ObjectVariable = "object"
+	// WorkloadVariable are the fields computed by k8s-reporter, e.g. workload.memoryLimits.
+	WorkloadVariable = "workload"
+)
+
+// Rule is a custom rule of the --rules file: a CEL expression that is true when a workload complies.
+type Rule struct {
+	ID         string `json:"id"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Expression string `json:"expression"`
+	// Kinds restricts the rule to some workload kinds, all kinds when empty.
+	Kinds   []string `json:"kinds,omitempty"`
+	program cel.Program
+}
+
+// RuleSet is the content of the --rules file.
+type RuleSet struct {
+	Rules []Rule `json:"rules"`
+}
+
+// rules are the custom rules of this run, nil unless --rules is set.
+var rules []Rule
+
+// LoadRules reads a YAML rules file and compiles the CEL expression of every rule.
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ruleSet RuleSet
+	if err := yaml.UnmarshalStrict(data, &ruleSet); err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %w", path, err)
+	}
+
+	env, err := cel.NewEnv(
+		cel.Variable(ObjectVariable, cel.DynType),
+		cel.Variable(WorkloadVariable, cel.DynType),
+		cel.CrossTypeNumericComparisons(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for i := range ruleSet.Rules {
+		rule := &ruleSet.Rules[i]
+		if rule.ID == "" {
+			return nil, fmt.Errorf("invalid rules file %s: rule %d has no id", path, i+1)
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("invalid rules file %s: duplicate rule %s", path, rule.ID)
+		}
+		seen[rule.ID] = true
+		if rule.Severity == "" {
+			rule.Severity = SeverityWarning
+		}
+		if !ValidSeverity(rule.Severity) {
+			return nil, fmt.Errorf("invalid rule %s: severity %q must be error, warning or info", rule.ID, rule.Severity)
+		}
+		if rule.Message == "" {
+			rule.Message = fmt.Sprintf("Rule %s is not satisfied: %s", rule.ID, rule.Expression)
+		}
+
+		ast, issues := env.Compile(rule.Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("invalid rule %s: %w", rule.ID, issues.Err())
+		}
+		if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+			return nil, fmt.Errorf("invalid rule %s: expression must return a bool, not %s", rule.ID, ast.OutputType())
+		}
+		rule.program, err = env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %s: %w", rule.ID, err)
+		}
+	}
+	Info("Loaded rules", zap.String("path", path), zap.Int("count", len(ruleSet.Rules)))
+	return ruleSet.Rules, nil
+}
+
+// AppliesTo reports whether the rule is evaluated for a workload kind.
+func (r Rule) AppliesTo(kind string) bool {
+	if len(r.Kinds) == 0 {
+		return true
+	}
+	for _, k := range r.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate evaluates the expression of the rule with the given variables, and reports whether it holds.
+func (r Rule) Evaluate(variables map[string]interface{}) (bool, error) {
+	value, _, err := r.program.Eval(variables)
+	if err != nil {
+		return false, err
+	}
+	compliant, ok := value.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression returned %v, not a bool", value.Value())
+	}
+	return compliant, nil
+}
+
+// SetRules sets the custom rules evaluated for every workload.
+func SetRules(ruleSet []Rule) {
+	rules = ruleSet
+}
+
+// GetRules returns the custom rules of this run.
+func GetRules() []Rule {
+	return rules
+}
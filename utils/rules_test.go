@@ -0,0 +1,118 @@
+// utils/rules_test.go
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRules(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("writing rules: %v", err)
+	}
+	return path
+}
+
+func TestLoadRules(t *testing.T) {
+	rules, err := LoadRules(writeRules(t, `
+rules:
+  - id: memory-limits
+    severity: error
+    message: Containers must have memory limits
+    expression: workload.memoryLimits > 0
+    kinds: [Deployment, StatefulSet]
+  - id: team-label
+    expression: has(object.metadata.labels) && 'team' in object.metadata.labels
+`))
+	if err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("loaded %d rules, want 2", len(rules))
+	}
+	if rules[0].Severity != SeverityError || rules[0].Message != "Containers must have memory limits" {
+		t.Errorf("first rule is %+v", rules[0])
+	}
+	// severity and message default
+	if rules[1].Severity != SeverityWarning {
+		t.Errorf("default severity is %q, want %q", rules[1].Severity, SeverityWarning)
+	}
+	if !strings.Contains(rules[1].Message, "team-label") {
+		t.Errorf("default message %q does not name the rule", rules[1].Message)
+	}
+
+	if !rules[0].AppliesTo("StatefulSet") || rules[0].AppliesTo("Job") {
+		t.Error("the kinds of the first rule are not honored")
+	}
+	if !rules[1].AppliesTo("Job") {
+		t.Error("a rule without kinds does not apply to every kind")
+	}
+}
+
+func TestLoadRulesErrors(t *testing.T) {
+	for name, test := range map[string]struct {
+		content string
+		want    string
+	}{
+		"unknown field":      {"rules:\n  - id: a\n    expresion: true\n", "unknown field"},
+		"missing id":         {"rules:\n  - expression: true\n", "rule 1 has no id"},
+		"duplicate id":       {"rules:\n  - id: a\n    expression: true\n  - id: a\n    expression: false\n", "duplicate rule a"},
+		"invalid severity":   {"rules:\n  - id: a\n    severity: critical\n    expression: true\n", `severity "critical"`},
+		"invalid expression": {"rules:\n  - id: a\n    expression: workload.replicas >\n", "invalid rule a"},
+		"undeclared name":    {"rules:\n  - id: a\n    expression: pod.replicas > 1\n", "undeclared reference"},
+		"non-bool result":    {"rules:\n  - id: a\n    expression: 1 + 1\n", "must return a bool, not int"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadRules(writeRules(t, test.content))
+			if err == nil {
+				t.Fatal("LoadRules succeeded")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error %q does not mention %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestRuleEvaluate(t *testing.T) {
+	rules, err := LoadRules(writeRules(t, `
+rules:
+  - id: replicas
+    expression: workload.replicas >= 2
+  - id: labels
+    expression: object.metadata.labels
+  - id: missing
+    expression: object.spec.paused
+`))
+	if err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+	variables := func(replicas int) map[string]interface{} {
+		return map[string]interface{}{
+			ObjectVariable:   map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"team": "shop"}}, "spec": map[string]interface{}{}},
+			WorkloadVariable: map[string]interface{}{"replicas": replicas},
+		}
+	}
+
+	for replicas, want := range map[int]bool{1: false, 2: true, 3: true} {
+		compliant, err := rules[0].Evaluate(variables(replicas))
+		if err != nil {
+			t.Fatalf("Evaluate: %v", err)
+		}
+		if compliant != want {
+			t.Errorf("with %d replicas, compliant is %v, want %v", replicas, compliant, want)
+		}
+	}
+	// a dynamic expression is only known not to return a bool once evaluated
+	if _, err := rules[1].Evaluate(variables(1)); err == nil || !strings.Contains(err.Error(), "not a bool") {
+		t.Errorf("Evaluate of a map returned %v, want a not a bool error", err)
+	}
+	if _, err := rules[2].Evaluate(variables(1)); err == nil {
+		t.Error("Evaluate of a missing field succeeded")
+	}
+}
@@ -0,0 +1,125 @@
+// utils/sarif.go
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// Annotations naming the manifest file a resource was read from. SourceAnnotation is set by
+// k8s-reporter itself, SourcePathAnnotation by kpt and kustomize functions.
+const (
+	SourceAnnotation     = "k8s-reporter.io/source"
+	SourcePathAnnotation = "config.kubernetes.io/path"
+)
+
+// SARIF 2.1.0 log, limited to what the findings need.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+type (
+	SARIFLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []SARIFRun `json:"runs"`
+	}
+
+	SARIFRun struct {
+		Tool    SARIFTool     `json:"tool"`
+		Results []SARIFResult `json:"results"`
+	}
+
+	SARIFTool struct {
+		Driver SARIFDriver `json:"driver"`
+	}
+
+	SARIFDriver struct {
+		Name  string      `json:"name"`
+		Rules []SARIFRule `json:"rules"`
+	}
+
+	SARIFRule struct {
+		ID                   string                 `json:"id"`
+		ShortDescription     SARIFMessage           `json:"shortDescription"`
+		DefaultConfiguration SARIFConfiguration     `json:"defaultConfiguration"`
+		Properties           map[string]interface{} `json:"properties,omitempty"`
+	}
+
+	SARIFConfiguration struct {
+		Level string `json:"level"`
+	}
+
+	SARIFMessage struct {
+		Text string `json:"text"`
+	}
+
+	SARIFResult struct {
+		RuleID              string            `json:"ruleId"`
+		RuleIndex           int               `json:"ruleIndex"`
+		Level               string            `json:"level"`
+		Message             SARIFMessage      `json:"message"`
+		Locations           []SARIFLocation   `json:"locations"`
+		PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	}
+
+	SARIFLocation struct {
+		PhysicalLocation SARIFPhysicalLocation  `json:"physicalLocation"`
+		LogicalLocations []SARIFLogicalLocation `json:"logicalLocations,omitempty"`
+	}
+
+	SARIFPhysicalLocation struct {
+		ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	}
+
+	SARIFArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+
+	SARIFLogicalLocation struct {
+		Name               string `json:"name"`
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+		Kind               string `json:"kind"`
+	}
+)
+
+// NewSARIFLog returns a SARIF log with a single, empty, run of k8s-reporter.
+func NewSARIFLog() *SARIFLog {
+	return &SARIFLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []SARIFRun{{
+			Tool: SARIFTool{Driver: SARIFDriver{
+				Name:  "k8s-reporter",
+				Rules: []SARIFRule{},
+			}},
+			Results: []SARIFResult{},
+		}},
+	}
+}
+
+// ResourceURI returns the synthetic URI locating a resource that was not read from a manifest file,
+// e.g. "k8s://default/Deployment/web".
+func ResourceURI(namespace string, kind string, name string) string {
+	return fmt.Sprintf("k8s://%s/%s/%s", url.PathEscape(namespace), url.PathEscape(kind), url.PathEscape(name))
+}
+
+// WriteSARIFLog writes a SARIF log to a JSON file.
+func WriteSARIFLog(log *SARIFLog, fileName string) error {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return err
+	}
+	if err := os.WriteFile(fileName, data.Bytes(), 0644); err != nil {
+		Error("Failed to write SARIF log", zap.String("fileName", fileName), zap.Error(err))
+		return err
+	}
+	Info("SARIF log written", zap.String("fileName", fileName), zap.Int("results", len(log.Runs[0].Results)))
+	return nil
+}
@@ -0,0 +1,23 @@
+// utils/severity.go
+
+package utils
+
+// Severities of the findings and of the custom rules, from the most to the least severe.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// severityRanks orders the severities, the most severe first.
+var severityRanks = map[string]int{SeverityError: 3, SeverityWarning: 2, SeverityInfo: 1}
+
+// ValidSeverity reports whether a severity is error, warning or info.
+func ValidSeverity(severity string) bool {
+	return severityRanks[severity] > 0
+}
+
+// SeverityAtLeast reports whether a severity is as severe as a threshold, e.g. an error is at least a warning.
+func SeverityAtLeast(severity string, threshold string) bool {
+	return severityRanks[severity] >= severityRanks[threshold]
+}
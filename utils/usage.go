@@ -0,0 +1,156 @@
+// utils/usage.go
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// ResourceUsage is the current usage reported by the metrics.k8s.io API for a group of pods or a node.
+type ResourceUsage struct {
+	// Pods is the number of pods the usage was summed over, zero for a node.
+	Pods   int
+	CPU    resource.Quantity
+	Memory resource.Quantity
+}
+
+// usageCache holds the current usage of every workload and node. It is filled with a single list
+// of PodMetrics, Pods and NodeMetrics, shared by all handlers.
+type usageCache struct {
+	mutex         sync.Mutex
+	once          sync.Once
+	metricsClient metricsclientset.Interface
+	workloads     map[string]*ResourceUsage
+	nodes         map[string]*ResourceUsage
+}
+
+var usageMetrics = &usageCache{}
+
+// EnableUsage turns on the reporting of the current usage, read from the metrics.k8s.io API with the given client.
+func EnableUsage(metricsClient metricsclientset.Interface) {
+	usageMetrics.mutex.Lock()
+	defer usageMetrics.mutex.Unlock()
+	if usageMetrics.metricsClient == nil {
+		usageMetrics.metricsClient = metricsClient
+	}
+}
+
+// DisableUsage turns off the reporting of the current usage and forgets the usage read so far.
+func DisableUsage() {
+	usageMetrics.mutex.Lock()
+	defer usageMetrics.mutex.Unlock()
+	usageMetrics.metricsClient = nil
+	usageMetrics.once = sync.Once{}
+	usageMetrics.workloads = nil
+	usageMetrics.nodes = nil
+}
+
+// UsageEnabled reports whether the current usage is reported.
+func UsageEnabled() bool {
+	usageMetrics.mutex.Lock()
+	defer usageMetrics.mutex.Unlock()
+	return usageMetrics.metricsClient != nil
+}
+
+// workloadUsageKey identifies a workload in the usage cache.
+func workloadUsageKey(kind string, namespace string, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// prefetch lists the metrics of all pods and nodes and sums the pod usage per owner.
+// The usage of a pod is added to every owner in its chain, e.g. both the Job and the CronJob of a pod,
+// so that each row has its usage: sums over several workloads must leave out the Jobs of a CronJob.
+func (c *usageCache) prefetch(clientset kubernetes.Interface) {
+	c.once.Do(func() {
+		c.workloads = map[string]*ResourceUsage{}
+		c.nodes = map[string]*ResourceUsage{}
+		ctx := context.Background()
+
+		podMetrics, err := c.metricsClient.MetricsV1beta1().PodMetricses("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			Error("Failed to list pod metrics, is metrics-server installed?", zap.Error(err))
+			return
+		}
+		pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			Error("Failed to list pods for their metrics", zap.Error(err))
+			return
+		}
+		podsByKey := make(map[string]v1.Pod, len(pods.Items))
+		for _, pod := range pods.Items {
+			podsByKey[pod.Namespace+"/"+pod.Name] = pod
+		}
+
+		for _, metrics := range podMetrics.Items {
+			pod, ok := podsByKey[metrics.Namespace+"/"+metrics.Name]
+			if !ok {
+				continue
+			}
+			var cpu, memory resource.Quantity
+			for _, container := range metrics.Containers {
+				cpu.Add(*container.Usage.Cpu())
+				memory.Add(*container.Usage.Memory())
+			}
+
+			chain, _ := ResolveOwnerChain(clientset, pod.ObjectMeta)
+			for _, owner := range chain {
+				key := workloadUsageKey(owner.Kind, pod.Namespace, owner.Name)
+				if c.workloads[key] == nil {
+					c.workloads[key] = &ResourceUsage{}
+				}
+				c.workloads[key].Pods++
+				c.workloads[key].CPU.Add(cpu)
+				c.workloads[key].Memory.Add(memory)
+			}
+		}
+		Info("Prefetched pod metrics", zap.Int("count", len(podMetrics.Items)))
+
+		nodeMetrics, err := c.metricsClient.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			Error("Failed to list node metrics", zap.Error(err))
+			return
+		}
+		for _, metrics := range nodeMetrics.Items {
+			c.nodes[metrics.Name] = &ResourceUsage{
+				CPU:    metrics.Usage.Cpu().DeepCopy(),
+				Memory: metrics.Usage.Memory().DeepCopy(),
+			}
+		}
+		Info("Prefetched node metrics", zap.Int("count", len(nodeMetrics.Items)))
+	})
+}
+
+// GetWorkloadUsage returns the current usage summed over the running pods of a workload, or nil when
+// usage reporting is disabled or no metrics were found for its pods.
+func GetWorkloadUsage(clientset kubernetes.Interface, kind string, namespace string, name string) *ResourceUsage {
+	if !UsageEnabled() {
+		return nil
+	}
+	usageMetrics.prefetch(clientset)
+
+	usageMetrics.mutex.Lock()
+	defer usageMetrics.mutex.Unlock()
+	return usageMetrics.workloads[workloadUsageKey(kind, namespace, name)]
+}
+
+// GetNodeUsage returns the current usage of a node, or nil when usage reporting is disabled or no
+// metrics were found for the node.
+func GetNodeUsage(clientset kubernetes.Interface, nodeName string) *ResourceUsage {
+	if !UsageEnabled() {
+		return nil
+	}
+	usageMetrics.prefetch(clientset)
+
+	usageMetrics.mutex.Lock()
+	defer usageMetrics.mutex.Unlock()
+	return usageMetrics.nodes[nodeName]
+}
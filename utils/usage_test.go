@@ -0,0 +1,159 @@
+// utils/usage_test.go
+
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// resetUsage enables usage reporting with the given metrics client on a fresh cache.
+func resetUsage(t *testing.T, metricsClient *metricsfake.Clientset) {
+	t.Helper()
+	DisableUsage()
+	EnableUsage(metricsClient)
+	t.Cleanup(DisableUsage)
+}
+
+// addPodMetrics adds the metrics of a pod to the fake metrics clientset, which lists PodMetrics under
+// the "pods" resource and NodeMetrics under the "nodes" one.
+func addPodMetrics(t *testing.T, metricsClient *metricsfake.Clientset, namespace string, name string, cpu string, memory string) {
+	t.Helper()
+	metrics := &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{Name: "app", Usage: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu), v1.ResourceMemory: resource.MustParse(memory)}},
+			{Name: "sidecar", Usage: v1.ResourceList{v1.ResourceCPU: resource.MustParse("10m"), v1.ResourceMemory: resource.MustParse("16Mi")}},
+		},
+	}
+	if err := metricsClient.Tracker().Create(metricsv1beta1.SchemeGroupVersion.WithResource("pods"), metrics, namespace); err != nil {
+		t.Fatalf("adding pod metrics: %v", err)
+	}
+}
+
+func addNodeMetrics(t *testing.T, metricsClient *metricsfake.Clientset, name string, cpu string, memory string) {
+	t.Helper()
+	metrics := &metricsv1beta1.NodeMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Usage:      v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu), v1.ResourceMemory: resource.MustParse(memory)},
+	}
+	if err := metricsClient.Tracker().Create(metricsv1beta1.SchemeGroupVersion.WithResource("nodes"), metrics, ""); err != nil {
+		t.Fatalf("adding node metrics: %v", err)
+	}
+}
+
+func controlledBy(kind string, name string) []metav1.OwnerReference {
+	isController := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, UID: types.UID("uid-" + name), Controller: &isController}}
+}
+
+// usageClientset returns a cluster with a Deployment running two pods through its ReplicaSet, and a
+// bare pod owned by nothing.
+func usageClientset() *fake.Clientset {
+	return fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "usage-web", Namespace: "shop"}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "usage-web-5d4f8", Namespace: "shop", OwnerReferences: controlledBy("Deployment", "usage-web")}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "usage-web-5d4f8-a", Namespace: "shop", OwnerReferences: controlledBy("ReplicaSet", "usage-web-5d4f8")}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "usage-web-5d4f8-b", Namespace: "shop", OwnerReferences: controlledBy("ReplicaSet", "usage-web-5d4f8")}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "usage-debug", Namespace: "shop"}},
+	)
+}
+
+func TestGetWorkloadUsage(t *testing.T) {
+	metricsClient := metricsfake.NewSimpleClientset()
+	addPodMetrics(t, metricsClient, "shop", "usage-web-5d4f8-a", "200m", "128Mi")
+	addPodMetrics(t, metricsClient, "shop", "usage-web-5d4f8-b", "300m", "256Mi")
+	addPodMetrics(t, metricsClient, "shop", "usage-debug", "50m", "32Mi")
+	// metrics of a pod that is gone since
+	addPodMetrics(t, metricsClient, "shop", "usage-web-5d4f8-c", "1", "1Gi")
+	resetUsage(t, metricsClient)
+	clientset := usageClientset()
+
+	usage := GetWorkloadUsage(clientset, "Deployment", "shop", "usage-web")
+	if usage == nil {
+		t.Fatal("no usage for the Deployment")
+	}
+	if usage.Pods != 2 {
+		t.Errorf("usage summed over %d pods, want 2", usage.Pods)
+	}
+	if got := CPUMillicores(usage.CPU); got != 520 {
+		t.Errorf("CPU usage is %dm, want 520m", got)
+	}
+	if got := MemoryMiB(usage.Memory); got != 416 {
+		t.Errorf("memory usage is %vMiB, want 416MiB", got)
+	}
+
+	// Every owner in the chain gets the usage of the pod, the ReplicaSet in between too.
+	if usage := GetWorkloadUsage(clientset, "ReplicaSet", "shop", "usage-web-5d4f8"); usage == nil || usage.Pods != 2 {
+		t.Errorf("usage of the ReplicaSet is %+v, want the usage of its 2 pods", usage)
+	}
+	if usage := GetWorkloadUsage(clientset, "Deployment", "other", "usage-web"); usage != nil {
+		t.Errorf("usage attributed to a Deployment of another namespace: %+v", usage)
+	}
+}
+
+func TestGetNodeUsage(t *testing.T) {
+	metricsClient := metricsfake.NewSimpleClientset()
+	addNodeMetrics(t, metricsClient, "usage-node-1", "1500m", "3Gi")
+	resetUsage(t, metricsClient)
+	clientset := usageClientset()
+
+	usage := GetNodeUsage(clientset, "usage-node-1")
+	if usage == nil {
+		t.Fatal("no usage for the node")
+	}
+	if got := CPUMillicores(usage.CPU); got != 1500 {
+		t.Errorf("CPU usage is %dm, want 1500m", got)
+	}
+	if got := MemoryMiB(usage.Memory); got != 3072 {
+		t.Errorf("memory usage is %vMiB, want 3072MiB", got)
+	}
+	if usage.Pods != 0 {
+		t.Errorf("node usage counts %d pods, want 0", usage.Pods)
+	}
+	if usage := GetNodeUsage(clientset, "usage-node-2"); usage != nil {
+		t.Errorf("usage reported for a node without metrics: %+v", usage)
+	}
+}
+
+func TestUsageWithoutMetricsAPI(t *testing.T) {
+	metricsClient := metricsfake.NewSimpleClientset()
+	addNodeMetrics(t, metricsClient, "usage-node-1", "1500m", "3Gi")
+	metricsClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("the server could not find the requested resource")
+	})
+	resetUsage(t, metricsClient)
+	clientset := usageClientset()
+
+	if usage := GetWorkloadUsage(clientset, "Deployment", "shop", "usage-web"); usage != nil {
+		t.Errorf("usage reported without the metrics API: %+v", usage)
+	}
+	if usage := GetNodeUsage(clientset, "usage-node-1"); usage != nil {
+		t.Errorf("node usage reported without the metrics API: %+v", usage)
+	}
+}
+
+func TestUsageDisabled(t *testing.T) {
+	DisableUsage()
+	clientset := usageClientset()
+	if UsageEnabled() {
+		t.Fatal("usage enabled without a metrics client")
+	}
+	if usage := GetWorkloadUsage(clientset, "Deployment", "shop", "usage-web"); usage != nil {
+		t.Errorf("usage reported while disabled: %+v", usage)
+	}
+	if usage := GetNodeUsage(clientset, "usage-node-1"); usage != nil {
+		t.Errorf("node usage reported while disabled: %+v", usage)
+	}
+}
@@ -0,0 +1,219 @@
+// utils/vulndb.go
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// sqliteMagic starts every SQLite database file.
+const sqliteMagic = "SQLite format 3\x00"
+
+// Vulnerability is a known vulnerability of some versions of an image.
+type Vulnerability struct {
+	ID string `json:"id"`
+	// Severity is the severity given by the database, e.g. CRITICAL, HIGH, MEDIUM, LOW.
+	Severity         string `json:"severity"`
+	Title            string `json:"title,omitempty"`
+	Package          string `json:"package,omitempty"`
+	InstalledVersion string `json:"installedVersion,omitempty"`
+	FixedVersion     string `json:"fixedVersion,omitempty"`
+	ImageMatcher
+}
+
+// BannedImage is an image, or some versions of it, that must not run.
+type BannedImage struct {
+	Reason string `json:"reason,omitempty"`
+	ImageMatcher
+}
+
+// ImageMatcher matches the references of an image, e.g. "nginx", to some of its tags and digests,
+// or to all its versions when both are empty.
+type ImageMatcher struct {
+	Image   string   `json:"image"`
+	Tags    []string `json:"tags,omitempty"`
+	Digests []string `json:"digests,omitempty"`
+	name    string
+}
+
+// VulnerabilityDB is the local database given with --vuln-db.
+type VulnerabilityDB struct {
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+	Banned          []BannedImage   `json:"banned"`
+}
+
+// trivyReport is the part of a Trivy JSON report (trivy image --format json) the database reads.
+type trivyReport struct {
+	ArtifactName string `json:"ArtifactName"`
+	Metadata     struct {
+		RepoDigests []string `json:"RepoDigests"`
+	} `json:"Metadata"`
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+			Title            string `json:"Title"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// vulnerabilityDB is the database of this run, nil unless --vuln-db is set.
+var vulnerabilityDB *VulnerabilityDB
+
+// LoadVulnerabilityDB reads a vulnerability database from a local file: either the native JSON format,
+// an object with "vulnerabilities" and "banned" images, Trivy JSON reports (a single report or an array),
+// or a SQLite database with the same vulnerabilities and banned tables. No network access is needed.
+func LoadVulnerabilityDB(path string) (*VulnerabilityDB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &VulnerabilityDB{}
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(data, []byte(sqliteMagic)):
+		if db, err = loadSQLiteVulnerabilityDB(path); err != nil {
+			return nil, fmt.Errorf("invalid vulnerability database %s: %w", path, err)
+		}
+	case bytes.HasPrefix(data, []byte("[")):
+		var reports []trivyReport
+		if err := json.Unmarshal(data, &reports); err != nil {
+			return nil, fmt.Errorf("invalid vulnerability database %s: %w", path, err)
+		}
+		for _, report := range reports {
+			db.addTrivyReport(report)
+		}
+	case bytes.Contains(data, []byte(`"ArtifactName"`)):
+		var report trivyReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("invalid vulnerability database %s: %w", path, err)
+		}
+		db.addTrivyReport(report)
+	default:
+		if err := json.Unmarshal(data, db); err != nil {
+			return nil, fmt.Errorf("invalid vulnerability database %s: %w", path, err)
+		}
+	}
+
+	for i := range db.Vulnerabilities {
+		if err := db.Vulnerabilities[i].normalize(); err != nil {
+			return nil, fmt.Errorf("invalid vulnerability %s in %s: %w", db.Vulnerabilities[i].ID, path, err)
+		}
+	}
+	for i := range db.Banned {
+		if err := db.Banned[i].normalize(); err != nil {
+			return nil, fmt.Errorf("invalid banned image in %s: %w", path, err)
+		}
+	}
+	Info("Loaded vulnerability database", zap.String("path", path), zap.Int("vulnerabilities", len(db.Vulnerabilities)), zap.Int("banned", len(db.Banned)))
+	return db, nil
+}
+
+// addTrivyReport adds the vulnerabilities of the image scanned by a Trivy report.
+func (db *VulnerabilityDB) addTrivyReport(report trivyReport) {
+	matcher := ImageMatcher{Image: report.ArtifactName}
+	if reference, err := ParseImageReference(report.ArtifactName); err == nil {
+		matcher.Image = reference.Name()
+		if reference.Tag != "" {
+			matcher.Tags = []string{reference.Tag}
+		}
+		if reference.Digest != "" {
+			matcher.Digests = []string{reference.Digest}
+		}
+	}
+	for _, repoDigest := range report.Metadata.RepoDigests {
+		if _, digest, ok := strings.Cut(repoDigest, "@"); ok {
+			matcher.Digests = append(matcher.Digests, digest)
+		}
+	}
+	for _, result := range report.Results {
+		for _, vulnerability := range result.Vulnerabilities {
+			db.Vulnerabilities = append(db.Vulnerabilities, Vulnerability{
+				ID:               vulnerability.VulnerabilityID,
+				Severity:         vulnerability.Severity,
+				Title:            vulnerability.Title,
+				Package:          vulnerability.PkgName,
+				InstalledVersion: vulnerability.InstalledVersion,
+				FixedVersion:     vulnerability.FixedVersion,
+				ImageMatcher:     matcher,
+			})
+		}
+	}
+}
+
+// normalize parses the image of the matcher, so that "nginx" matches "docker.io/library/nginx".
+func (m *ImageMatcher) normalize() error {
+	if m.Image == "" {
+		return errors.New("missing image")
+	}
+	reference, err := ParseImageReference(m.Image)
+	if err != nil {
+		return err
+	}
+	m.name = reference.Name()
+	return nil
+}
+
+// Matches reports whether an image reference is one of the versions of the matcher.
+func (m ImageMatcher) Matches(reference ImageReference) bool {
+	if reference.Name() != m.name {
+		return false
+	}
+	if len(m.Tags) == 0 && len(m.Digests) == 0 {
+		return true
+	}
+	for _, tag := range m.Tags {
+		if reference.Tag == tag || (tag == latestTag && reference.Tag == "" && reference.Digest == "") {
+			return true
+		}
+	}
+	for _, digest := range m.Digests {
+		if reference.Digest == digest {
+			return true
+		}
+	}
+	return false
+}
+
+// VulnerabilitiesOf returns the known vulnerabilities of an image.
+func (db *VulnerabilityDB) VulnerabilitiesOf(reference ImageReference) []Vulnerability {
+	var matches []Vulnerability
+	for _, vulnerability := range db.Vulnerabilities {
+		if vulnerability.Matches(reference) {
+			matches = append(matches, vulnerability)
+		}
+	}
+	return matches
+}
+
+// BannedBy returns the entries banning an image.
+func (db *VulnerabilityDB) BannedBy(reference ImageReference) []BannedImage {
+	var matches []BannedImage
+	for _, banned := range db.Banned {
+		if banned.Matches(reference) {
+			matches = append(matches, banned)
+		}
+	}
+	return matches
+}
+
+// SetVulnerabilityDB sets the vulnerability database images are matched against.
+func SetVulnerabilityDB(db *VulnerabilityDB) {
+	vulnerabilityDB = db
+}
+
+// GetVulnerabilityDB returns the vulnerability database of this run, or nil when images are not matched.
+func GetVulnerabilityDB() *VulnerabilityDB {
+	return vulnerabilityDB
+}
@@ -0,0 +1,141 @@
+// utils/vulndb_sqlite.go
+
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	// Pure Go SQLite driver, so that k8s-reporter builds without cgo.
+	_ "modernc.org/sqlite"
+)
+
+// vulnerabilityRow is a row of the vulnerabilities table of a SQLite vulnerability database.
+type vulnerabilityRow struct {
+	id, severity, title, pkg, installedVersion, fixedVersion string
+	image, tag, digest                                       string
+}
+
+// bannedRow is a row of the banned table of a SQLite vulnerability database.
+type bannedRow struct {
+	image, tag, digest, reason string
+}
+
+// loadSQLiteVulnerabilityDB reads a vulnerability database from a SQLite file with the tables
+//
+//	vulnerabilities (id, severity, title, package, installed_version, fixed_version, image, tag, digest)
+//	banned (image, tag, digest, reason)
+//
+// Either table may be missing. A row matches one tag or digest of its image, or all its versions when
+// both are empty; the rows of a vulnerability (or banned image) differing only by their tag or digest
+// are merged.
+func loadSQLiteVulnerabilityDB(path string) (*VulnerabilityDB, error) {
+	conn, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	tables, err := sqliteTables(conn)
+	if err != nil {
+		return nil, err
+	}
+	if !tables["vulnerabilities"] && !tables["banned"] {
+		return nil, fmt.Errorf("no vulnerabilities or banned table")
+	}
+
+	db := &VulnerabilityDB{}
+	if tables["vulnerabilities"] {
+		rows, err := conn.Query(`SELECT id, COALESCE(severity, ''), COALESCE(title, ''), COALESCE(package, ''),
+			COALESCE(installed_version, ''), COALESCE(fixed_version, ''), COALESCE(image, ''), COALESCE(tag, ''), COALESCE(digest, '')
+			FROM vulnerabilities ORDER BY rowid`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the vulnerabilities table: %w", err)
+		}
+		defer rows.Close()
+		indexes := map[vulnerabilityRow]int{}
+		for rows.Next() {
+			var row vulnerabilityRow
+			if err := rows.Scan(&row.id, &row.severity, &row.title, &row.pkg, &row.installedVersion, &row.fixedVersion, &row.image, &row.tag, &row.digest); err != nil {
+				return nil, fmt.Errorf("failed to read the vulnerabilities table: %w", err)
+			}
+			key := row
+			key.tag, key.digest = "", ""
+			i, ok := indexes[key]
+			if !ok {
+				i = len(db.Vulnerabilities)
+				indexes[key] = i
+				db.Vulnerabilities = append(db.Vulnerabilities, Vulnerability{
+					ID:               row.id,
+					Severity:         strings.ToUpper(row.severity),
+					Title:            row.title,
+					Package:          row.pkg,
+					InstalledVersion: row.installedVersion,
+					FixedVersion:     row.fixedVersion,
+					ImageMatcher:     ImageMatcher{Image: row.image},
+				})
+			}
+			db.Vulnerabilities[i].addVersion(row.tag, row.digest)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read the vulnerabilities table: %w", err)
+		}
+	}
+
+	if tables["banned"] {
+		rows, err := conn.Query(`SELECT COALESCE(image, ''), COALESCE(tag, ''), COALESCE(digest, ''), COALESCE(reason, '') FROM banned ORDER BY rowid`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the banned table: %w", err)
+		}
+		defer rows.Close()
+		indexes := map[bannedRow]int{}
+		for rows.Next() {
+			var row bannedRow
+			if err := rows.Scan(&row.image, &row.tag, &row.digest, &row.reason); err != nil {
+				return nil, fmt.Errorf("failed to read the banned table: %w", err)
+			}
+			key := row
+			key.tag, key.digest = "", ""
+			i, ok := indexes[key]
+			if !ok {
+				i = len(db.Banned)
+				indexes[key] = i
+				db.Banned = append(db.Banned, BannedImage{Reason: row.reason, ImageMatcher: ImageMatcher{Image: row.image}})
+			}
+			db.Banned[i].addVersion(row.tag, row.digest)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read the banned table: %w", err)
+		}
+	}
+	return db, nil
+}
+
+// sqliteTables returns the names of the tables of a SQLite database.
+func sqliteTables(conn *sql.DB) (map[string]bool, error) {
+	rows, err := conn.Query(`SELECT name FROM sqlite_master WHERE type = 'table'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tables := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables[name] = true
+	}
+	return tables, rows.Err()
+}
+
+// addVersion adds a tag and a digest, when set, to the versions of the matcher.
+func (m *ImageMatcher) addVersion(tag string, digest string) {
+	if tag != "" {
+		m.Tags = append(m.Tags, tag)
+	}
+	if digest != "" {
+		m.Digests = append(m.Digests, digest)
+	}
+}
@@ -0,0 +1,111 @@
+// utils/vulndb_test.go
+
+package utils
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// writeSQLiteDB creates a SQLite database running the given statements.
+func writeSQLiteDB(t *testing.T, statements ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "vulndb.sqlite")
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("opening SQLite database: %v", err)
+	}
+	defer conn.Close()
+	for _, statement := range statements {
+		if _, err := conn.Exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	return path
+}
+
+func mustParseImage(t *testing.T, image string) ImageReference {
+	t.Helper()
+	reference, err := ParseImageReference(image)
+	if err != nil {
+		t.Fatalf("ParseImageReference(%q): %v", image, err)
+	}
+	return reference
+}
+
+func TestLoadSQLiteVulnerabilityDB(t *testing.T) {
+	path := writeSQLiteDB(t,
+		`CREATE TABLE vulnerabilities (id TEXT NOT NULL, severity TEXT, title TEXT, package TEXT, installed_version TEXT,
+			fixed_version TEXT, image TEXT NOT NULL, tag TEXT, digest TEXT)`,
+		`INSERT INTO vulnerabilities VALUES
+			('CVE-2023-44487', 'high', 'HTTP/2 rapid reset', 'nghttp2', '1.52.0', '1.57.0', 'nginx', '1.25.1', NULL),
+			('CVE-2023-44487', 'high', 'HTTP/2 rapid reset', 'nghttp2', '1.52.0', '1.57.0', 'nginx', '1.25.2', NULL),
+			('CVE-2023-44487', 'high', 'HTTP/2 rapid reset', 'nghttp2', '1.52.0', '1.57.0', 'nginx', NULL, 'sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31'),
+			('CVE-2024-0001', NULL, NULL, NULL, NULL, NULL, 'quay.io/acme/api', NULL, NULL)`,
+		`CREATE TABLE banned (image TEXT NOT NULL, tag TEXT, digest TEXT, reason TEXT)`,
+		`INSERT INTO banned VALUES ('busybox', 'latest', NULL, 'floating tag'), ('docker.io/library/redis', NULL, NULL, NULL)`,
+	)
+
+	db, err := LoadVulnerabilityDB(path)
+	if err != nil {
+		t.Fatalf("LoadVulnerabilityDB: %v", err)
+	}
+	if len(db.Vulnerabilities) != 2 || len(db.Banned) != 2 {
+		t.Fatalf("loaded %d vulnerabilities and %d banned images, want 2 and 2", len(db.Vulnerabilities), len(db.Banned))
+	}
+	rapidReset := db.Vulnerabilities[0]
+	if rapidReset.Severity != "HIGH" || rapidReset.FixedVersion != "1.57.0" || len(rapidReset.Tags) != 2 || len(rapidReset.Digests) != 1 {
+		t.Errorf("rows of CVE-2023-44487 are not merged: %+v", rapidReset)
+	}
+
+	for image, want := range map[string]int{
+		"nginx:1.25.1":                   1,
+		"docker.io/library/nginx:1.25.2": 1,
+		"nginx:1.25.3":                   0,
+		"nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31": 1,
+		"quay.io/acme/api:v2": 1,
+	} {
+		if got := len(db.VulnerabilitiesOf(mustParseImage(t, image))); got != want {
+			t.Errorf("%s has %d vulnerabilities, want %d", image, got, want)
+		}
+	}
+	for image, want := range map[string]int{
+		"busybox":      1,
+		"busybox:1.36": 0,
+		"redis:7":      1,
+		"nginx:1.25.1": 0,
+	} {
+		if got := len(db.BannedBy(mustParseImage(t, image))); got != want {
+			t.Errorf("%s is banned by %d entries, want %d", image, got, want)
+		}
+	}
+}
+
+func TestLoadSQLiteVulnerabilityDBWithOneTable(t *testing.T) {
+	path := writeSQLiteDB(t,
+		`CREATE TABLE banned (image TEXT NOT NULL, tag TEXT, digest TEXT, reason TEXT)`,
+		`INSERT INTO banned VALUES ('busybox', 'latest', NULL, 'floating tag')`,
+	)
+	db, err := LoadVulnerabilityDB(path)
+	if err != nil {
+		t.Fatalf("LoadVulnerabilityDB: %v", err)
+	}
+	if len(db.Vulnerabilities) != 0 || len(db.Banned) != 1 {
+		t.Errorf("loaded %d vulnerabilities and %d banned images, want 0 and 1", len(db.Vulnerabilities), len(db.Banned))
+	}
+}
+
+func TestLoadSQLiteVulnerabilityDBErrors(t *testing.T) {
+	for name, statements := range map[string][]string{
+		"no known table": {`CREATE TABLE images (name TEXT)`},
+		"missing column": {`CREATE TABLE vulnerabilities (id TEXT, image TEXT)`, `INSERT INTO vulnerabilities VALUES ('CVE-2024-0001', 'nginx')`},
+		"missing image":  {`CREATE TABLE banned (image TEXT, tag TEXT, digest TEXT, reason TEXT)`, `INSERT INTO banned VALUES (NULL, 'latest', NULL, NULL)`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadVulnerabilityDB(writeSQLiteDB(t, statements...)); err == nil {
+				t.Error("LoadVulnerabilityDB succeeded")
+			}
+		})
+	}
+}